@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"goreleaser-helper/internal/changelog"
+	"goreleaser-helper/internal/config"
+	"goreleaser-helper/internal/semver"
+	"goreleaser-helper/internal/tmpl"
+)
+
+var (
+	changelogVersion  string
+	changelogBump     string
+	changelogFrom     string
+	changelogTo       string
+	changelogRepo     string
+	changelogFormat   string
+	changelogTemplate string
+	changelogOutput   string
+	changelogWrite    bool
+	changelogBody     bool
+)
+
+var changelogCmd = &cobra.Command{
+	Use:   "changelog",
+	Short: "Print release notes for a range of commits",
+	Long: `Print the release notes for the commits between two refs, without building
+anything or calling the GitHub API. Without --from the notes start at the
+previous version tag; without --to they end at HEAD. The version defaults to
+--to if it is a version tag, "Unreleased" otherwise.
+
+The notes are printed to stdout, written to a file with --output, or added
+to the changelog file with --write.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if changelogOutput != "" && changelogWrite {
+			return fmt.Errorf("--output cannot be used with --write")
+		}
+		if changelogBody && changelogWrite {
+			return fmt.Errorf("--release-body cannot be used with --write")
+		}
+
+		cfg, gen, err := changelogGenerator()
+		if err != nil {
+			return err
+		}
+
+		version, err := changelogNotesVersion(gen)
+		if err != nil {
+			return err
+		}
+
+		if err := applyConfigTemplates(cfg, version); err != nil {
+			return err
+		}
+
+		notes, err := gen.NotesForRange(version, changelog.Range{From: changelogFrom, To: changelogTo})
+		if err != nil {
+			return fmt.Errorf("failed to generate changelog: %w", err)
+		}
+
+		if changelogWrite {
+			if err := gen.Write(notes); err != nil {
+				return err
+			}
+			color.Green("✅ Wrote %s to %s", version, cfg.Release.Changelog.Path)
+			return nil
+		}
+
+		// Render as the changelog file section, or as the GitHub release body
+		var content string
+		if changelogBody {
+			content = gen.ReleaseBody(notes)
+		} else if content, err = gen.Render(notes); err != nil {
+			return err
+		}
+
+		if changelogOutput == "" {
+			fmt.Print(content)
+			return nil
+		}
+		if err := os.WriteFile(changelogOutput, []byte(content), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", changelogOutput, err)
+		}
+		color.Green("✅ Wrote release notes to %s", changelogOutput)
+		return nil
+	},
+}
+
+// changelogNotesVersion returns the version the notes are for: the
+// --version flag, the bumped version, the --to tag or "Unreleased"
+func changelogNotesVersion(gen *changelog.Generator) (string, error) {
+	if changelogBump != "" {
+		if changelogVersion != "" {
+			return "", fmt.Errorf("--version cannot be used with --bump")
+		}
+		next, err := gen.NextVersion(changelogBump)
+		if err != nil {
+			return "", fmt.Errorf("failed to compute next version: %w", err)
+		}
+		return next, nil
+	}
+
+	if changelogVersion != "" {
+		v, err := semver.Parse(changelogVersion)
+		if err != nil {
+			return "", err
+		}
+		return v.String(), nil
+	}
+
+	if v, err := semver.Parse(changelogTo); err == nil {
+		return v.String(), nil
+	}
+	return changelog.Unreleased, nil
+}
+
+var changelogRegenerateCmd = &cobra.Command{
+	Use:   "regenerate",
+	Short: "Rebuild the changelog file from all version tags",
+	Long: `Rebuild the changelog file from the history of every version tag. The notes
+of a stable release start at the previous stable release, so they include
+the changes of its prereleases. Existing sections for the same versions are
+replaced.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, gen, err := changelogGenerator()
+		if err != nil {
+			return err
+		}
+
+		history, err := gen.History()
+		if err != nil {
+			return fmt.Errorf("failed to generate changelog: %w", err)
+		}
+		if len(history) == 0 {
+			return fmt.Errorf("no version tags found")
+		}
+
+		// Templated paths are rendered for the newest release
+		if err := applyConfigTemplates(cfg, history[0].Version); err != nil {
+			return err
+		}
+
+		if err := gen.Write(history...); err != nil {
+			return err
+		}
+
+		color.Green("✅ Wrote %d releases to %s", len(history), cfg.Release.Changelog.Path)
+		return nil
+	},
+}
+
+// changelogGenerator loads the configuration and creates the generator
+// shared by the changelog commands
+func changelogGenerator() (*config.Config, *changelog.Generator, error) {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if changelogRepo == "" {
+		changelogRepo = cfg.GitHub.DefaultRepo
+	}
+
+	// Override the changelog format from flags, a format replaces the
+	// configured template
+	if changelogFormat != "" {
+		if _, err := changelog.NewFormatter(changelogFormat); err != nil {
+			return nil, nil, err
+		}
+		cfg.Release.Changelog.Format = changelogFormat
+		cfg.Release.Changelog.Template = ""
+	}
+	if changelogTemplate != "" {
+		cfg.Release.Changelog.Template = changelogTemplate
+	}
+
+	return cfg, changelog.NewGenerator(cfg, changelogRepo), nil
+}
+
+// applyConfigTemplates renders the templated config values, such as the
+// changelog path and the release notes header, for the given version
+func applyConfigTemplates(cfg *config.Config, version string) error {
+	tmplCtx, err := tmpl.New(cfg.Project.Name, version)
+	if err != nil {
+		return fmt.Errorf("failed to prepare template context: %w", err)
+	}
+	if err := cfg.ProcessTemplate(tmplCtx); err != nil {
+		return fmt.Errorf("failed to process config templates: %w", err)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(changelogCmd)
+	changelogCmd.AddCommand(changelogRegenerateCmd)
+
+	changelogCmd.PersistentFlags().StringVarP(&configPath, "config", "c", "goreleaser.yaml", "Path to configuration file")
+	changelogCmd.PersistentFlags().StringVarP(&changelogRepo, "repo", "r", "", "GitHub repository (owner/repo) used for links")
+	changelogCmd.PersistentFlags().StringVarP(&changelogFormat, "format", "f", "", "Changelog format: markdown, keepachangelog, json or yaml (overrides release.changelog.format)")
+	changelogCmd.PersistentFlags().StringVar(&changelogTemplate, "template", "", "Render the changelog with this template file (overrides release.changelog.template)")
+
+	changelogCmd.Flags().StringVarP(&changelogVersion, "version", "v", "", "Version the notes are for")
+	changelogCmd.Flags().StringVarP(&changelogBump, "bump", "b", "", "Compute the version from the last tag: auto, major, minor, patch or prerelease")
+	changelogCmd.Flags().StringVar(&changelogFrom, "from", "", "Start after this ref (default: previous release tag)")
+	changelogCmd.Flags().StringVar(&changelogTo, "to", "", "End at this ref (default: HEAD)")
+	changelogCmd.Flags().StringVarP(&changelogOutput, "output", "o", "", "Write the notes to this file instead of stdout")
+	changelogCmd.Flags().BoolVarP(&changelogWrite, "write", "w", false, "Add the notes to the changelog file (release.changelog.path)")
+	changelogCmd.Flags().BoolVar(&changelogBody, "release-body", false, "Render the notes as the GitHub release body, with the configured header and footer")
+}
@@ -3,13 +3,22 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 
+	"goreleaser-helper/internal/archive"
+	"goreleaser-helper/internal/assets"
 	"goreleaser-helper/internal/build"
 	"goreleaser-helper/internal/changelog"
+	"goreleaser-helper/internal/checksum"
 	"goreleaser-helper/internal/config"
+	"goreleaser-helper/internal/git"
 	"goreleaser-helper/internal/github"
+	"goreleaser-helper/internal/semver"
+	"goreleaser-helper/internal/sign"
+	"goreleaser-helper/internal/tmpl"
 )
 
 var (
@@ -17,6 +26,16 @@ var (
 	repo        string
 	configPath  string
 	generateChg bool
+	dryRun      bool
+	snapshot    bool
+	apiURL      string
+	uploadURL   string
+	notesPath   string
+	draft       bool
+	prerelease  bool
+	existing    string
+	skipTag     bool
+	bump        string
 )
 
 var releaseCmd = &cobra.Command{
@@ -35,25 +54,139 @@ var releaseCmd = &cobra.Command{
 			repo = cfg.GitHub.DefaultRepo
 		}
 
+		// Override GitHub endpoints from flags
+		if apiURL != "" {
+			cfg.GitHub.APIURL = apiURL
+		}
+		if uploadURL != "" {
+			cfg.GitHub.UploadURL = uploadURL
+		}
+
+		// Override release settings from flags
+		if cmd.Flags().Changed("draft") {
+			cfg.Release.Draft = draft
+		}
+		if cmd.Flags().Changed("prerelease") {
+			cfg.Release.Prerelease = fmt.Sprintf("%t", prerelease)
+		}
+		if existing != "" {
+			if existing != "fail" && existing != "keep" && existing != "update" {
+				return fmt.Errorf("invalid --existing mode: %s", existing)
+			}
+			cfg.Release.Existing = existing
+		}
+
+		// Snapshots generate their own version and cannot be bumped
+		if snapshot && bump != "" {
+			return fmt.Errorf("--bump cannot be used with --snapshot")
+		}
+
+		// Compute the version from the commits if requested
+		if bump != "" {
+			if version != "" {
+				return fmt.Errorf("--version cannot be used with --bump")
+			}
+			if version, err = changelog.NewGenerator(cfg, repo).NextVersion(bump); err != nil {
+				return fmt.Errorf("failed to compute next version: %w", err)
+			}
+			color.Blue("🔢 Next version: %s", version)
+		}
+
+		// Snapshots use a generated version
+		if snapshot {
+			if version != "" {
+				return fmt.Errorf("--version cannot be used with --snapshot")
+			}
+			if version, err = tmpl.SnapshotVersion(); err != nil {
+				return err
+			}
+		}
+
 		// Check required flags
 		if version == "" {
 			return fmt.Errorf("version is required")
 		}
-		if repo == "" {
+		parsed, err := semver.Parse(version)
+		if err != nil {
+			return err
+		}
+		// Use the canonical form so that "v1.0.0" and "1.0.0" both release v1.0.0
+		version = parsed.String()
+		if repo == "" && !snapshot {
 			return fmt.Errorf("repository is required")
 		}
 
-		// Check for GitHub token
+		// Check for GitHub token, only needed when publishing
 		token := os.Getenv(cfg.GitHub.TokenEnv)
-		if token == "" {
+		if token == "" && !dryRun && !snapshot {
 			return fmt.Errorf("GitHub token not found in environment variable %s", cfg.GitHub.TokenEnv)
 		}
 
-		// Generate changelog if enabled
-		if cfg.Release.Changelog.Enabled || generateChg {
+		// Verify the tag can be created before running any stage, since
+		// hooks and builds may modify the worktree. The changelog is
+		// committed along with the tag.
+		writeChangelog := cfg.Release.Changelog.Enabled || generateChg
+		tagOpts := git.TagOptions{
+			Tag:    parsed.Tag(),
+			Branch: cfg.Release.DefaultBranch,
+			Remote: cfg.Release.Tag.Remote,
+			Sign:   cfg.Release.Tag.Sign,
+		}
+		if writeChangelog {
+			tagOpts.Files = []string{cfg.Release.Changelog.Path}
+		}
+		createTag := !snapshot && !skipTag && !cfg.Release.Tag.Skip
+		if createTag {
+			if err := git.VerifyTaggable(tagOpts); err != nil {
+				if !dryRun {
+					return err
+				}
+				color.Yellow("🧪 Dry run: %v", err)
+			}
+		}
+
+		// Prepare template context
+		tmplCtx, err := tmpl.New(cfg.Project.Name, version)
+		if err != nil {
+			return fmt.Errorf("failed to prepare template context: %w", err)
+		}
+		if err := cfg.ProcessTemplate(tmplCtx); err != nil {
+			return fmt.Errorf("failed to process config templates: %w", err)
+		}
+
+		// Generate release notes, writing the changelog file if enabled.
+		// Dry runs print it instead of touching the file, snapshots skip
+		// release notes entirely.
+		var body string
+		if !snapshot {
 			gen := changelog.NewGenerator(cfg, repo)
-			if err := gen.Generate(version); err != nil {
-				return fmt.Errorf("failed to generate changelog: %w", err)
+			if notesPath != "" {
+				if body, err = gen.ReleaseBodyFromFile(notesPath); err != nil {
+					return err
+				}
+			}
+
+			if body == "" || writeChangelog {
+				notes, err := gen.Notes(version)
+				if err != nil {
+					return fmt.Errorf("failed to generate changelog: %w", err)
+				}
+				if body == "" {
+					body = gen.ReleaseBody(notes)
+				}
+
+				if writeChangelog {
+					if dryRun {
+						content, err := gen.Render(notes)
+						if err != nil {
+							return fmt.Errorf("failed to generate changelog: %w", err)
+						}
+						color.Yellow("🧪 Dry run: changelog not written to %s", cfg.Release.Changelog.Path)
+						fmt.Println(content)
+					} else if err := gen.Write(notes); err != nil {
+						return fmt.Errorf("failed to generate changelog: %w", err)
+					}
+				}
 			}
 		}
 
@@ -63,6 +196,7 @@ var releaseCmd = &cobra.Command{
 			Config:   cfg,
 			MainFile: cfg.Build.MainFile,
 			LdFlags:  cfg.Build.LdFlags,
+			Template: tmplCtx,
 		}
 
 		binaries, err := build.BuildBinaries(buildOpts)
@@ -70,30 +204,130 @@ var releaseCmd = &cobra.Command{
 			return fmt.Errorf("failed to build binaries: %w", err)
 		}
 
+		// Package binaries into archives
+		archives, err := archive.Archive(archive.Options{
+			Config:   cfg,
+			Binaries: binaries,
+			Template: tmplCtx,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create archives: %w", err)
+		}
+
+		// Write checksums for all artifacts
+		checksums, err := checksum.Write(checksum.Options{
+			Algorithm: cfg.Checksum.Algorithm,
+			Path:      filepath.Join(cfg.Build.OutputDir, version, cfg.Checksum.Name),
+			Artifacts: archives,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to write checksums: %w", err)
+		}
+
+		// Sign the checksums file and, if configured, every archive
+		var signatures []assets.Asset
+		if cfg.Release.Sign.Enabled {
+			toSign := []assets.Asset{checksums}
+			if cfg.Release.Sign.Artifacts == "all" {
+				toSign = append(toSign, archives...)
+			}
+			signatures, err = sign.SignArtifacts(sign.Options{
+				KeyType:    cfg.Release.Sign.Type,
+				KeyPath:    cfg.Release.Sign.Key,
+				Passphrase: signingPassphrase(cfg),
+				Artifacts:  toSign,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to sign artifacts: %w", err)
+			}
+		}
+
+		// Collect extra release assets
+		extraAssets, err := assets.Collect(assets.Options{
+			Include: cfg.Release.Assets.Include,
+			Exclude: cfg.Release.Assets.Exclude,
+			Strict:  cfg.Release.Assets.Strict,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to collect assets: %w", err)
+		}
+
+		if snapshot {
+			color.Green("✅ Snapshot %s built in %s", version, filepath.Join(cfg.Build.OutputDir, version))
+			return nil
+		}
+
+		// Create and push the release tag
+		if createTag {
+			tagOpts.Message = fmt.Sprintf("Release %s\n\n%s", tagOpts.Tag, body)
+			if dryRun {
+				color.Yellow("🧪 Dry run: not creating tag %s", tagOpts.Tag)
+			} else if err := git.CreateAndPushTag(tagOpts); err != nil {
+				return err
+			}
+		}
+
 		// Create GitHub release
 		releaseOpts := github.ReleaseOptions{
-			Version:  version,
-			Repo:     repo,
-			Token:    token,
-			Binaries: binaries,
-			Config:   cfg,
+			Version: version,
+			Body:    body,
+			Repo:    repo,
+			Token:   token,
+			Assets:  releaseAssets(archives, checksums, signatures, extraAssets),
+			Config:  cfg,
+			DryRun:  dryRun,
 		}
 
 		if err := github.CreateRelease(releaseOpts); err != nil {
 			return fmt.Errorf("failed to create release: %w", err)
 		}
+		if dryRun {
+			return nil
+		}
 
 		fmt.Printf("Successfully created release %s\n", version)
 		return nil
 	},
 }
 
+// releaseAssets combines the artifacts of every stage into the upload set
+func releaseAssets(archives []assets.Asset, checksums assets.Asset, signatures, extra []assets.Asset) []assets.Asset {
+	var result []assets.Asset
+	result = append(result, archives...)
+	result = append(result, checksums)
+	result = append(result, signatures...)
+	result = append(result, extra...)
+	return result
+}
+
+// signingPassphrase reads the signing key passphrase from the configured
+// environment variable, falling back to the deprecated plaintext setting
+func signingPassphrase(cfg *config.Config) string {
+	if pass := os.Getenv(cfg.Release.Sign.PassEnv); pass != "" {
+		return pass
+	}
+	if cfg.Release.Sign.Pass != "" {
+		color.Yellow("⚠️  release.sign.pass stores the passphrase in plaintext, use release.sign.passEnv instead")
+	}
+	return cfg.Release.Sign.Pass
+}
+
 func init() {
 	rootCmd.AddCommand(releaseCmd)
 
 	// Add flags
 	releaseCmd.Flags().StringVarP(&version, "version", "v", "", "Version to release")
+	releaseCmd.Flags().StringVarP(&bump, "bump", "b", "", "Compute the version from the last tag: auto, major, minor, patch or prerelease")
 	releaseCmd.Flags().StringVarP(&repo, "repo", "r", "", "GitHub repository (owner/repo)")
 	releaseCmd.Flags().StringVarP(&configPath, "config", "c", "goreleaser.yaml", "Path to configuration file")
 	releaseCmd.Flags().BoolVarP(&generateChg, "changelog", "g", false, "Generate changelog")
+	releaseCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Run every stage and print the release without publishing")
+	releaseCmd.Flags().StringVar(&notesPath, "release-notes", "", "Use the contents of this file as the release body")
+	releaseCmd.Flags().BoolVar(&draft, "draft", false, "Leave the release as a draft (overrides release.draft)")
+	releaseCmd.Flags().BoolVar(&prerelease, "prerelease", false, "Mark the release as a prerelease (overrides release.prerelease)")
+	releaseCmd.Flags().StringVar(&existing, "existing", "", "What to do if the release already exists: fail, keep or update (overrides release.existing)")
+	releaseCmd.Flags().BoolVar(&skipTag, "skip-tag", false, "Do not create and push the release tag")
+	releaseCmd.Flags().StringVar(&apiURL, "api-url", "", "GitHub API base URL (overrides github.apiURL)")
+	releaseCmd.Flags().StringVar(&uploadURL, "upload-url", "", "GitHub upload base URL (overrides github.uploadURL)")
+	releaseCmd.Flags().BoolVar(&snapshot, "snapshot", false, "Build with a generated snapshot version and skip publishing")
 }
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"goreleaser-helper/internal/checksum"
+)
+
+var (
+	verifyDir       string
+	verifyAlgorithm string
+)
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify <checksums-file>",
+	Short: "Verify files against a checksums file",
+	Long: `Verify local files against a checksums file in sha256sum format,
+such as the checksums.txt published with a release`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Files are resolved next to the checksums file by default
+		dir := verifyDir
+		if dir == "" {
+			dir = filepath.Dir(args[0])
+		}
+
+		results, err := checksum.Verify(args[0], dir, verifyAlgorithm)
+		if err != nil {
+			return err
+		}
+
+		failed := 0
+		for _, result := range results {
+			switch {
+			case result.Err != nil:
+				color.Red("%s: FAILED open or read (%v)", result.Name, result.Err)
+				failed++
+			case !result.OK:
+				color.Red("%s: FAILED", result.Name)
+				failed++
+			default:
+				color.Green("%s: OK", result.Name)
+			}
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("%d of %d files failed verification", failed, len(results))
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+
+	verifyCmd.Flags().StringVarP(&verifyDir, "dir", "d", "", "Directory containing the files (defaults to the checksums file directory)")
+	verifyCmd.Flags().StringVarP(&verifyAlgorithm, "algorithm", "a", "", "Checksum algorithm (sha256, sha512, blake2b); sha256 is inferred when omitted")
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"goreleaser-helper/internal/changelog"
+	"goreleaser-helper/internal/config"
+)
+
+var nextBump string
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Inspect release versions",
+}
+
+var versionNextCmd = &cobra.Command{
+	Use:   "next",
+	Short: "Print the next version",
+	Long: `Print the version following the last tag. With --bump auto the bump is
+derived from the conventional commits since that tag.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load(configPath)
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		next, err := changelog.NewGenerator(cfg, "").NextVersion(nextBump)
+		if err != nil {
+			return fmt.Errorf("failed to compute next version: %w", err)
+		}
+
+		fmt.Println(next)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(versionCmd)
+	versionCmd.AddCommand(versionNextCmd)
+
+	versionNextCmd.Flags().StringVarP(&nextBump, "bump", "b", changelog.BumpAuto, "Bump to apply: auto, major, minor, patch or prerelease")
+	versionNextCmd.Flags().StringVarP(&configPath, "config", "c", "goreleaser.yaml", "Path to configuration file")
+}
@@ -0,0 +1,240 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/fatih/color"
+
+	"goreleaser-helper/internal/assets"
+	"goreleaser-helper/internal/build"
+	"goreleaser-helper/internal/config"
+	"goreleaser-helper/internal/tmpl"
+)
+
+// Supported archive formats
+const (
+	FormatTarGz  = "tar.gz"
+	FormatZip    = "zip"
+	FormatBinary = "binary" // Upload the raw binary without archiving
+)
+
+// Options contains the options for archiving build results
+type Options struct {
+	Config   *config.Config
+	Binaries []build.BuildResult
+	Template tmpl.Context
+}
+
+// entry is a single file stored in an archive
+type entry struct {
+	Name string // Slash-separated path inside the archive
+	Path string // Path of the file on disk
+	Mode os.FileMode
+}
+
+// Archive packages every build result together with the configured extra
+// files and returns the resulting release assets. Archives are
+// reproducible: entries are sorted, ownership is cleared and every
+// timestamp is set to the commit date.
+func Archive(opts Options) ([]assets.Asset, error) {
+	cfg := opts.Config.Archive
+
+	// Resolve extra files once, they are shared by every archive
+	files, err := resolveFiles(cfg.Files)
+	if err != nil {
+		return nil, err
+	}
+
+	mtime := modTime(opts.Template)
+
+	color.Blue("📦 Creating archives for %d binaries...", len(opts.Binaries))
+
+	var result []assets.Asset
+	targets := make(map[string]string) // Asset name to the target that produced it
+	for _, binary := range opts.Binaries {
+		format := formatFor(opts.Config, binary.Platform)
+		if format == FormatBinary {
+			name := filepath.Base(binary.Path)
+			if err := claimName(targets, name, binary); err != nil {
+				return nil, err
+			}
+			result = append(result, assets.Asset{Name: name, Path: binary.Path})
+			continue
+		}
+
+		// Render the archive name for this target
+		ctx := opts.Template.WithPlatform(binary.Platform, binary.Arch)
+		name, err := ctx.Apply(cfg.NameTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render archive name: %w", err)
+		}
+		name += "." + format
+		path := filepath.Join(filepath.Dir(binary.Path), name)
+
+		// Check before writing, a duplicate would overwrite the other archive
+		if err := claimName(targets, name, binary); err != nil {
+			return nil, err
+		}
+
+		// The binary is stored under the project name rather than the
+		// platform-suffixed build output name
+		binaryName := opts.Config.Project.Name
+		if binary.Platform == "windows" {
+			binaryName += ".exe"
+		}
+		entries := append([]entry{{Name: binaryName, Path: binary.Path, Mode: 0755}}, files...)
+		sort.Slice(entries, func(i, j int) bool {
+			return entries[i].Name < entries[j].Name
+		})
+
+		if err := create(path, format, entries, mtime); err != nil {
+			return nil, fmt.Errorf("failed to create archive %s: %w", name, err)
+		}
+		result = append(result, assets.Asset{Name: name, Path: path})
+	}
+
+	color.Green("✅ Archives created successfully!")
+	return result, nil
+}
+
+// claimName records that the binary's target produces the asset name,
+// failing if another target already does
+func claimName(targets map[string]string, name string, binary build.BuildResult) error {
+	target := binary.Platform + "/" + binary.Arch
+	if other, ok := targets[name]; ok {
+		return fmt.Errorf("archive name %s is used by both %s and %s, make archive.nameTemplate unique per target", name, other, target)
+	}
+	targets[name] = target
+	return nil
+}
+
+func formatFor(cfg *config.Config, goos string) string {
+	for _, override := range cfg.Archive.FormatOverrides {
+		if override.OS == goos {
+			return override.Format
+		}
+	}
+	return cfg.Archive.Format
+}
+
+func resolveFiles(patterns []string) ([]entry, error) {
+	var files []entry
+	seen := make(map[string]bool)
+	for _, pattern := range patterns {
+		matches, err := assets.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid archive file pattern %q: %w", pattern, err)
+		}
+		for _, match := range matches {
+			name := filepath.ToSlash(filepath.Clean(match))
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			files = append(files, entry{Name: name, Path: match, Mode: 0644})
+		}
+	}
+	return files, nil
+}
+
+// modTime returns the timestamp stored in archives, derived from the commit
+// date so that rebuilding the same commit produces identical archives
+func modTime(ctx tmpl.Context) time.Time {
+	if t, err := time.Parse(time.RFC3339, ctx.CommitDate); err == nil {
+		return t.UTC()
+	}
+	return time.Unix(0, 0).UTC()
+}
+
+func create(path, format string, entries []entry, mtime time.Time) error {
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	switch format {
+	case FormatTarGz:
+		err = writeTarGz(out, entries, mtime)
+	case FormatZip:
+		err = writeZip(out, entries, mtime)
+	default:
+		err = fmt.Errorf("unsupported archive format: %s", format)
+	}
+	if err != nil {
+		return err
+	}
+
+	return out.Close()
+}
+
+func writeTarGz(w io.Writer, entries []entry, mtime time.Time) error {
+	gw := gzip.NewWriter(w)
+	gw.ModTime = mtime
+	tw := tar.NewWriter(gw)
+
+	for _, e := range entries {
+		info, err := os.Stat(e.Path)
+		if err != nil {
+			return err
+		}
+		header := &tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     e.Name,
+			Size:     info.Size(),
+			Mode:     int64(e.Mode),
+			ModTime:  mtime,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if err := copyFile(tw, e.Path); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+func writeZip(w io.Writer, entries []entry, mtime time.Time) error {
+	zw := zip.NewWriter(w)
+
+	for _, e := range entries {
+		header := &zip.FileHeader{
+			Name:     e.Name,
+			Method:   zip.Deflate,
+			Modified: mtime,
+		}
+		header.SetMode(e.Mode)
+		fw, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		if err := copyFile(fw, e.Path); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+func copyFile(w io.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
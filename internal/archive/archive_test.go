@@ -0,0 +1,92 @@
+package archive
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"goreleaser-helper/internal/build"
+	"goreleaser-helper/internal/config"
+	"goreleaser-helper/internal/tmpl"
+)
+
+func TestArchiveNames(t *testing.T) {
+	tests := []struct {
+		name         string
+		nameTemplate string
+		binaryOS     string // Uploaded as a raw binary
+		wantNames    []string
+		wantErr      []string
+	}{
+		{
+			name:         "unique names",
+			nameTemplate: "{{.ProjectName}}_{{.Os}}_{{.Arch}}",
+			wantNames:    []string{"demo_linux_amd64.tar.gz", "demo_linux_arm64.tar.gz"},
+		},
+		{
+			name:         "duplicate names",
+			nameTemplate: "{{.ProjectName}}_{{.Os}}",
+			wantErr:      []string{"demo_linux.tar.gz", "linux/amd64", "linux/arm64"},
+		},
+		{
+			name:         "duplicate raw binaries",
+			nameTemplate: "{{.ProjectName}}_{{.Os}}",
+			binaryOS:     "linux",
+			wantErr:      []string{"demo", "linux/amd64", "linux/arm64"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.Project.Name = "demo"
+			cfg.Archive.Format = FormatTarGz
+			cfg.Archive.NameTemplate = tt.nameTemplate
+			if tt.binaryOS != "" {
+				cfg.Archive.FormatOverrides = append(cfg.Archive.FormatOverrides, struct {
+					OS     string `yaml:"os"`
+					Format string `yaml:"format"`
+				}{OS: tt.binaryOS, Format: FormatBinary})
+			}
+
+			// Raw binaries keep the base name of their path
+			var binaries []build.BuildResult
+			for _, arch := range []string{"amd64", "arm64"} {
+				dir := filepath.Join(t.TempDir(), arch)
+				if err := os.MkdirAll(dir, 0755); err != nil {
+					t.Fatal(err)
+				}
+				path := filepath.Join(dir, "demo")
+				if err := os.WriteFile(path, []byte(arch), 0755); err != nil {
+					t.Fatal(err)
+				}
+				binaries = append(binaries, build.BuildResult{Path: path, Platform: "linux", Arch: arch})
+			}
+
+			result, err := Archive(Options{Config: cfg, Binaries: binaries, Template: tmpl.Context{ProjectName: "demo"}})
+			if tt.wantErr != nil {
+				if err == nil {
+					t.Fatalf("Archive() succeeded with %+v", result)
+				}
+				for _, want := range tt.wantErr {
+					if !strings.Contains(err.Error(), want) {
+						t.Errorf("error %q does not mention %s", err, want)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var names []string
+			for _, asset := range result {
+				names = append(names, asset.Name)
+			}
+			if strings.Join(names, " ") != strings.Join(tt.wantNames, " ") {
+				t.Errorf("names = %q, want %q", names, tt.wantNames)
+			}
+		})
+	}
+}
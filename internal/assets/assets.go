@@ -0,0 +1,192 @@
+package assets
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Asset represents a file to be uploaded to a release
+type Asset struct {
+	Name string // Name of the asset in the release
+	Path string // Path of the file on disk
+}
+
+// Options contains the options for collecting extra release assets
+type Options struct {
+	Include []string // Glob patterns for files to include, "**" matches any number of directories
+	Exclude []string // Glob patterns for files to exclude
+	Strict  bool     // Fail when an include pattern matches no files
+}
+
+// Collect resolves the include patterns relative to the working directory,
+// drops files matching an exclude pattern and returns the remaining files
+// sorted by name
+func Collect(opts Options) ([]Asset, error) {
+	var result []Asset
+	seen := make(map[string]string)
+
+	for _, pattern := range opts.Include {
+		matches, err := Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid include pattern %q: %w", pattern, err)
+		}
+
+		matched := 0
+		for _, match := range matches {
+			excluded, err := isExcluded(match, opts.Exclude)
+			if err != nil {
+				return nil, err
+			}
+			if excluded {
+				continue
+			}
+			matched++
+
+			// Dedupe by asset name, since a release cannot hold two assets
+			// with the same name
+			name := filepath.Base(match)
+			if existing, ok := seen[name]; ok {
+				if existing != match {
+					return nil, fmt.Errorf("asset name %s is used by both %s and %s", name, existing, match)
+				}
+				continue
+			}
+			seen[name] = match
+			result = append(result, Asset{Name: name, Path: match})
+		}
+
+		if matched == 0 && opts.Strict {
+			return nil, fmt.Errorf("include pattern %q did not match any files", pattern)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	return result, nil
+}
+
+// Glob returns the regular files matching pattern. In addition to the
+// syntax supported by path.Match, a "**" segment matches zero or more
+// directories.
+func Glob(pattern string) ([]string, error) {
+	pattern = filepath.ToSlash(filepath.Clean(pattern))
+
+	// Plain paths need no directory walk
+	if !hasMeta(pattern) {
+		info, err := os.Stat(filepath.FromSlash(pattern))
+		if err != nil || !info.Mode().IsRegular() {
+			return nil, nil
+		}
+		return []string{filepath.FromSlash(pattern)}, nil
+	}
+
+	// Validate the pattern up front so errors are not swallowed by the walk
+	for _, segment := range strings.Split(pattern, "/") {
+		if _, err := path.Match(segment, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	root := staticPrefix(pattern)
+	var matches []string
+	err := filepath.WalkDir(filepath.FromSlash(root), func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if Match(pattern, filepath.ToSlash(p)) {
+			matches = append(matches, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return matches, nil
+}
+
+// Match reports whether the slash-separated name matches pattern, with "**"
+// segments matching zero or more path segments
+func Match(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// Try every possible number of segments for "**"
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// isExcluded reports whether file matches one of the exclude patterns.
+// Patterns without a slash are also matched against the base name so that
+// "*.tmp" excludes temporary files in any directory.
+func isExcluded(file string, exclude []string) (bool, error) {
+	name := filepath.ToSlash(filepath.Clean(file))
+	for _, pattern := range exclude {
+		pattern = filepath.ToSlash(filepath.Clean(pattern))
+		if _, err := path.Match(strings.ReplaceAll(pattern, "**", "*"), ""); err != nil {
+			return false, fmt.Errorf("invalid exclude pattern %q: %w", pattern, err)
+		}
+		if Match(pattern, name) {
+			return true, nil
+		}
+		if !strings.Contains(pattern, "/") && Match(pattern, path.Base(name)) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// staticPrefix returns the leading directories of pattern that contain no
+// glob metacharacters
+func staticPrefix(pattern string) string {
+	segments := strings.Split(pattern, "/")
+	var prefix []string
+	for _, segment := range segments[:len(segments)-1] {
+		if hasMeta(segment) {
+			break
+		}
+		prefix = append(prefix, segment)
+	}
+	if len(prefix) == 0 {
+		return "."
+	}
+	if prefix[0] == "" {
+		// Absolute pattern
+		return "/" + path.Join(prefix[1:]...)
+	}
+	return path.Join(prefix...)
+}
+
+func hasMeta(s string) bool {
+	return strings.ContainsAny(s, `*?[\`)
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/schollz/progressbar/v3"
 
 	"goreleaser-helper/internal/config"
+	"goreleaser-helper/internal/tmpl"
 )
 
 // Platform represents a build target platform
@@ -35,6 +36,7 @@ type BuildOptions struct {
 	Config   *config.Config
 	MainFile string
 	LdFlags  string
+	Template tmpl.Context
 }
 
 // BuildResult represents the result of a build
@@ -55,6 +57,11 @@ func BuildBinaries(opts BuildOptions) ([]BuildResult, error) {
 		return nil, fmt.Errorf("failed to create output directory: %w", err)
 	}
 
+	// Run before hooks
+	if err := RunHooks("before", opts.Config.Build.Before, opts.Template, buildEnv(opts.Config)); err != nil {
+		return nil, err
+	}
+
 	color.Blue("🔨 Building binaries for %d platforms...", len(opts.Config.Build.Platforms))
 
 	// Create progress bar
@@ -106,6 +113,12 @@ func BuildBinaries(opts BuildOptions) ([]BuildResult, error) {
 	}
 
 	color.Green("✅ All binaries built successfully!")
+
+	// Run after hooks
+	if err := RunHooks("after", opts.Config.Build.After, opts.Template, buildEnv(opts.Config)); err != nil {
+		return nil, err
+	}
+
 	return results, nil
 }
 
@@ -127,10 +140,17 @@ func buildForPlatform(opts BuildOptions, goos, arch, outputDir string) (BuildRes
 		outputPath += ".exe"
 	}
 
+	// Render ldflags for this target
+	ctx := opts.Template.WithPlatform(goos, arch)
+	ldflags, err := ctx.Apply(opts.LdFlags)
+	if err != nil {
+		return BuildResult{}, fmt.Errorf("failed to render ldflags: %w", err)
+	}
+
 	// Prepare build command
 	args := []string{"build", "-v"}
-	if opts.LdFlags != "" {
-		args = append(args, "-ldflags", opts.LdFlags)
+	if ldflags != "" {
+		args = append(args, "-ldflags", ldflags)
 	}
 	args = append(args, "-o", outputPath)
 	if opts.MainFile != "" {
@@ -147,9 +167,24 @@ func buildForPlatform(opts BuildOptions, goos, arch, outputDir string) (BuildRes
 		return BuildResult{}, fmt.Errorf("build command failed: %w\nOutput: %s", err, string(output))
 	}
 
+	// Run per-target post-build hooks
+	if err := RunHooks("post-build", opts.Config.Build.PostBuild, ctx.WithPath(outputPath), env); err != nil {
+		return BuildResult{}, err
+	}
+
 	return BuildResult{
 		Path:     outputPath,
 		Platform: goos,
 		Arch:     arch,
 	}, nil
 }
+
+// buildEnv returns the environment used for hooks that are not tied to a
+// specific target: the process environment plus the configured build env
+func buildEnv(cfg *config.Config) []string {
+	env := os.Environ()
+	for k, v := range cfg.Build.Env {
+		env = append(env, fmt.Sprintf("%s=%s", k, v))
+	}
+	return env
+}
@@ -0,0 +1,50 @@
+package build
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/fatih/color"
+
+	"goreleaser-helper/internal/tmpl"
+)
+
+// RunHooks executes the given hook commands in order with the provided
+// environment, streaming their output and stopping at the first failure
+func RunHooks(stage string, commands []string, ctx tmpl.Context, env []string) error {
+	for _, command := range commands {
+		// Render the command template
+		rendered, err := ctx.Apply(command)
+		if err != nil {
+			return fmt.Errorf("invalid %s hook %q: %w", stage, command, err)
+		}
+		if strings.TrimSpace(rendered) == "" {
+			continue
+		}
+
+		color.Cyan("🪝 Running %s hook: %s", stage, rendered)
+
+		cmd := shellCommand(rendered)
+		cmd.Env = env
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("%s hook %q failed: %w", stage, rendered, err)
+		}
+	}
+
+	return nil
+}
+
+// shellCommand wraps a hook command in the platform shell so that pipes,
+// redirects and quoting behave as they would on the command line
+func shellCommand(command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.Command("cmd", "/C", command)
+	}
+	return exec.Command("sh", "-c", command)
+}
@@ -0,0 +1,108 @@
+package changelog
+
+import (
+	"fmt"
+
+	"goreleaser-helper/internal/semver"
+)
+
+// Supported bump kinds
+const (
+	BumpAuto       = "auto"
+	BumpMajor      = "major"
+	BumpMinor      = "minor"
+	BumpPatch      = "patch"
+	BumpPrerelease = "prerelease"
+)
+
+// NextVersion computes the version following the last version tag. With
+// BumpAuto the bump is derived from the commits since that tag: breaking
+// changes bump the major version, features the minor version and anything
+// else the patch version. Before 1.0.0 the versioning config decides how
+// breaking changes and features are bumped.
+func (g *Generator) NextVersion(bump string) (string, error) {
+	last, err := g.lastVersionTag("HEAD")
+	if err != nil {
+		return "", fmt.Errorf("failed to get last tag: %w", err)
+	}
+	current, lastTag := last.version, last.Name
+
+	var next semver.Version
+	switch bump {
+	case BumpMajor:
+		next = current.BumpMajor()
+	case BumpMinor:
+		next = current.BumpMinor()
+	case BumpPatch:
+		next = current.BumpPatch()
+	case BumpAuto, BumpPrerelease:
+		// A prerelease continues the current prerelease line if there is one
+		if bump == BumpPrerelease && current.Prerelease != "" {
+			if next, err = current.NextPrerelease(g.config.Versioning.PrereleaseID); err != nil {
+				return "", err
+			}
+			break
+		}
+
+		kind, err := g.commitBump(lastTag)
+		if err != nil {
+			return "", err
+		}
+		switch g.preMajorBump(current, kind) {
+		case BumpMajor:
+			next = current.BumpMajor()
+		case BumpMinor:
+			next = current.BumpMinor()
+		default:
+			next = current.BumpPatch()
+		}
+
+		// The first prerelease of the bumped version
+		if bump == BumpPrerelease {
+			next.Prerelease = g.config.Versioning.PrereleaseID + ".1"
+		}
+	default:
+		return "", fmt.Errorf("invalid bump: %s (expected auto, major, minor, patch or prerelease)", bump)
+	}
+
+	return next.String(), nil
+}
+
+// preMajorBump applies the pre-1.0 rules to the bump derived from commits
+func (g *Generator) preMajorBump(current semver.Version, kind string) string {
+	if current.Major > 0 {
+		return kind
+	}
+	switch kind {
+	case BumpMajor:
+		return g.config.Versioning.PreMajorBreaking
+	case BumpMinor:
+		return g.config.Versioning.PreMajorFeature
+	}
+	return kind
+}
+
+// commitBump returns the bump required by the commits since the given tag
+func (g *Generator) commitBump(since string) (string, error) {
+	entries, err := g.entries(since, "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("failed to get commits: %w", err)
+	}
+	if len(entries) == 0 {
+		if since == "" {
+			return "", fmt.Errorf("no commits in history")
+		}
+		return "", fmt.Errorf("no commits since %s", since)
+	}
+
+	kind := BumpPatch
+	for _, entry := range entries {
+		if entry.Breaking {
+			return BumpMajor, nil
+		}
+		if entry.Type == "feat" {
+			kind = BumpMinor
+		}
+	}
+	return kind, nil
+}
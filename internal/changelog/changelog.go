@@ -3,7 +3,6 @@ package changelog
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"regexp"
 	"sort"
@@ -11,6 +10,7 @@ import (
 	"time"
 
 	"goreleaser-helper/internal/config"
+	"goreleaser-helper/internal/git"
 )
 
 // Entry represents a single changelog entry
@@ -18,172 +18,334 @@ type Entry struct {
 	Type        string
 	Scope       string
 	Description string
+	Breaking    bool     // Marked with "!" or a BREAKING CHANGE footer
+	Body        string   // Commit body without the subject and footers
+	Footers     []Footer // Trailers such as "BREAKING CHANGE" or "Refs"
 	Hash        string
 	Author      string
+	CoAuthors   []string // Names from Co-authored-by trailers
 	Date        time.Time
 }
 
+// Footer is a single "Key: value" or "Key #value" commit message footer
+type Footer struct {
+	Key   string
+	Value string
+}
+
+// Subject returns the conventional commit subject line of the entry
+func (e Entry) Subject() string {
+	subject := e.Type
+	if e.Scope != "" {
+		subject += "(" + e.Scope + ")"
+	}
+	if e.Breaking {
+		subject += "!"
+	}
+	return subject + ": " + e.Description
+}
+
+// BreakingNote returns the explanation of a breaking change, taken from the
+// BREAKING CHANGE footer if present
+func (e Entry) BreakingNote() string {
+	for _, footer := range e.Footers {
+		if isBreakingKey(footer.Key) {
+			return footer.Value
+		}
+	}
+	return ""
+}
+
 // Generator handles changelog generation
 type Generator struct {
 	config *config.Config
 	repo   string
+	git    git.Repository
 }
 
-// NewGenerator creates a new changelog generator
+// NewGenerator creates a new changelog generator for the git repository in
+// the current directory
 func NewGenerator(cfg *config.Config, repo string) *Generator {
+	return NewGeneratorWithGit(cfg, repo, git.NewRepository(""))
+}
+
+// NewGeneratorWithGit creates a new changelog generator that reads the
+// history from the given repository
+func NewGeneratorWithGit(cfg *config.Config, repo string, history git.Repository) *Generator {
 	return &Generator{
 		config: cfg,
 		repo:   repo,
+		git:    history,
 	}
 }
 
-// Generate creates a changelog for the given version
-func (g *Generator) Generate(version string) error {
-	// Get the last tag
-	lastTag, err := g.getLastTag()
+// Generate creates the release notes for the given version and writes them
+// to the changelog file
+func (g *Generator) Generate(version string) (*ReleaseNotes, error) {
+	notes, err := g.Notes(version)
 	if err != nil {
-		return fmt.Errorf("failed to get last tag: %w", err)
+		return nil, err
 	}
 
-	// Get commits since last tag
-	entries, err := g.getCommits(lastTag)
-	if err != nil {
-		return fmt.Errorf("failed to get commits: %w", err)
+	if err := g.Write(notes); err != nil {
+		return nil, err
 	}
 
-	// Generate changelog content
-	content, err := g.formatChangelog(version, entries)
+	return notes, nil
+}
+
+// Notes collects the release notes for the given version from the commits
+// since the previous release
+func (g *Generator) Notes(version string) (*ReleaseNotes, error) {
+	return g.NotesForRange(version, Range{})
+}
+
+// repoURL returns the web URL of the repository on the configured GitHub
+// host, or an empty string if the repository is unknown
+func (g *Generator) repoURL() string {
+	if g.repo == "" {
+		return ""
+	}
+	return strings.TrimSuffix(g.config.GitHub.URL, "/") + "/" + config.RepoName(g.repo)
+}
+
+// compareURL returns the link to the commits between two tags, or an empty
+// string for the first release
+func (g *Generator) compareURL(from, to string) string {
+	repoURL := g.repoURL()
+	if repoURL == "" || from == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/compare/%s...%s", repoURL, from, to)
+}
+
+// formatter returns the formatter for the changelog file, preferring a
+// user-defined template over the built-in formats
+func (g *Generator) formatter() (Formatter, error) {
+	if path := g.config.Release.Changelog.Template; path != "" {
+		return NewTemplateFormatter(path)
+	}
+	return NewFormatter(g.config.Release.Changelog.Format)
+}
+
+// Render returns the changelog file section for the release notes in the
+// configured format
+func (g *Generator) Render(notes *ReleaseNotes) (string, error) {
+	formatter, err := g.formatter()
 	if err != nil {
-		return fmt.Errorf("failed to format changelog: %w", err)
+		return "", err
+	}
+	content, err := formatter.Format(notes)
+	if err != nil {
+		return "", fmt.Errorf("failed to format changelog: %w", err)
 	}
+	return content, nil
+}
 
+// Write renders the release notes and adds them to the changelog file
+func (g *Generator) Write(notes ...*ReleaseNotes) error {
 	// Write changelog file
-	if err := g.writeChangelog(content); err != nil {
+	if err := g.writeChangelog(notes); err != nil {
 		return fmt.Errorf("failed to write changelog: %w", err)
 	}
 
 	return nil
 }
 
-func (g *Generator) getLastTag() (string, error) {
-	cmd := exec.Command("git", "describe", "--tags", "--abbrev=0")
-	output, err := cmd.Output()
+// Merge renders the release notes and adds them to the contents of a
+// changelog file, replacing earlier sections for the same versions
+func (g *Generator) Merge(existing string, notes ...*ReleaseNotes) (string, error) {
+	formatter, err := g.formatter()
 	if err != nil {
-		// If no tags exist, return empty string
-		if strings.Contains(err.Error(), "No names found") {
-			return "", nil
-		}
 		return "", err
 	}
-	return strings.TrimSpace(string(output)), nil
+
+	content := existing
+	for _, n := range notes {
+		release, err := formatter.Format(n)
+		if err != nil {
+			return "", fmt.Errorf("failed to format changelog: %w", err)
+		}
+		if content, err = formatter.Merge(content, release); err != nil {
+			return "", fmt.Errorf("failed to update changelog: %w", err)
+		}
+	}
+	return content, nil
 }
 
-func (g *Generator) getCommits(since string) ([]Entry, error) {
-	args := []string{"log", "--pretty=format:%H|%an|%ad|%s"}
-	if since != "" {
-		args = append(args, since+"..HEAD")
+// ReleaseBody returns the release notes formatted for a GitHub release,
+// wrapped in the configured header and footer
+func (g *Generator) ReleaseBody(notes *ReleaseNotes) string {
+	return g.wrapBody(formatEntries(notes))
+}
+
+// wrapBody surrounds body with the configured header and footer
+func (g *Generator) wrapBody(body string) string {
+	var content strings.Builder
+	if header := g.config.Release.Notes.Header; header != "" {
+		content.WriteString(strings.TrimSpace(header) + "\n\n")
 	}
+	content.WriteString(strings.TrimSpace(body) + "\n")
+	if footer := g.config.Release.Notes.Footer; footer != "" {
+		content.WriteString("\n" + strings.TrimSpace(footer) + "\n")
+	}
+	return content.String()
+}
 
-	cmd := exec.Command("git", args...)
-	output, err := cmd.Output()
+// ReleaseBodyFromFile returns the contents of a hand-written release notes
+// file, wrapped in the configured header and footer
+func (g *Generator) ReleaseBodyFromFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
 	if err != nil {
-		return nil, err
+		return "", fmt.Errorf("failed to read release notes: %w", err)
 	}
+	return g.wrapBody(string(data)), nil
+}
 
-	var entries []Entry
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		parts := strings.Split(line, "|")
-		if len(parts) != 4 {
-			continue
-		}
-
-		hash := parts[0]
-		author := parts[1]
-		date, err := time.Parse("Mon Jan 2 15:04:05 2006 -0700", parts[2])
-		if err != nil {
-			continue
-		}
-		message := parts[3]
+// entries reads the commits between two refs and parses them as
+// conventional commits, newest first
+func (g *Generator) entries(from, to string) ([]Entry, error) {
+	commits, err := g.git.Commits(from, to)
+	if err != nil {
+		return nil, err
+	}
 
+	entries := make([]Entry, 0, len(commits))
+	for _, commit := range commits {
 		// Parse conventional commit message
-		entry := parseCommitMessage(message)
-		entry.Hash = hash
-		entry.Author = author
-		entry.Date = date
+		entry := parseCommitMessage(commit.Message)
+		entry.Hash = commit.Hash
+		entry.Author = commit.Author
+		entry.Date = commit.Date
+		for _, coAuthor := range commit.CoAuthors {
+			entry.CoAuthors = append(entry.CoAuthors, coAuthor.Name)
+		}
 
 		entries = append(entries, entry)
 	}
 
 	// Sort entries by date
-	sort.Slice(entries, func(i, j int) bool {
+	sort.SliceStable(entries, func(i, j int) bool {
 		return entries[i].Date.After(entries[j].Date)
 	})
 
 	return entries, nil
 }
 
+var (
+	// Conventional commit format: type(scope)!: description
+	subjectRegexp = regexp.MustCompile(`^(\w+)(?:\(([\w-]+)\))?(!)?:\s*(.+)$`)
+
+	// Footer format: "Key: value" or "Key #value", with BREAKING CHANGE as
+	// the only key allowed to contain a space
+	footerRegexp = regexp.MustCompile(`^(BREAKING CHANGE|[\w-]+)(?:: | #)(.*)$`)
+)
+
 func parseCommitMessage(message string) Entry {
-	// Conventional commit format: type(scope): description
-	re := regexp.MustCompile(`^(\w+)(?:\(([\w-]+)\))?:\s*(.+)$`)
-	matches := re.FindStringSubmatch(message)
+	message = strings.TrimSpace(strings.ReplaceAll(message, "\r\n", "\n"))
+	subject, rest, _ := strings.Cut(message, "\n")
+	subject = strings.TrimSpace(subject)
 
 	entry := Entry{
 		Type:        "other",
-		Description: message,
+		Description: subject,
 	}
 
-	if len(matches) >= 4 {
+	if matches := subjectRegexp.FindStringSubmatch(subject); matches != nil {
 		entry.Type = matches[1]
-		if matches[2] != "" {
-			entry.Scope = matches[2]
+		entry.Scope = matches[2]
+		entry.Breaking = matches[3] == "!"
+		entry.Description = matches[4]
+	}
+
+	entry.Body, entry.Footers = parseBody(strings.TrimSpace(rest))
+	for _, footer := range entry.Footers {
+		if isBreakingKey(footer.Key) {
+			entry.Breaking = true
 		}
-		entry.Description = matches[3]
 	}
 
 	return entry
 }
 
-func (g *Generator) formatChangelog(version string, entries []Entry) (string, error) {
-	var content strings.Builder
+// parseBody splits the text after the subject into the body and the
+// footers. Footers are the last paragraph if its first line is a footer;
+// lines that do not start a new footer continue the previous one.
+func parseBody(text string) (string, []Footer) {
+	if text == "" {
+		return "", nil
+	}
 
-	// Write header
-	content.WriteString(fmt.Sprintf("# Changelog for %s\n\n", version))
-	content.WriteString(fmt.Sprintf("Release date: %s\n\n", time.Now().Format("2006-01-02")))
+	paragraphs := strings.Split(text, "\n\n")
+	last := paragraphs[len(paragraphs)-1]
+	lines := strings.Split(last, "\n")
+	if !footerRegexp.MatchString(lines[0]) {
+		return text, nil
+	}
 
-	// Group entries by type
-	groups := make(map[string][]Entry)
-	for _, entry := range entries {
-		groups[entry.Type] = append(groups[entry.Type], entry)
+	var footers []Footer
+	for _, line := range lines {
+		if matches := footerRegexp.FindStringSubmatch(line); matches != nil {
+			footers = append(footers, Footer{Key: matches[1], Value: strings.TrimSpace(matches[2])})
+			continue
+		}
+		current := &footers[len(footers)-1]
+		current.Value = strings.TrimSpace(current.Value + "\n" + line)
 	}
 
-	// Write entries by type
-	types := []string{"feat", "fix", "docs", "style", "refactor", "perf", "test", "build", "ci", "chore", "other"}
-	for _, t := range types {
-		if entries, ok := groups[t]; ok {
-			content.WriteString(fmt.Sprintf("## %s\n\n", formatType(t)))
-			for _, entry := range entries {
-				scope := ""
-				if entry.Scope != "" {
-					scope = fmt.Sprintf("(%s) ", entry.Scope)
+	body := strings.TrimSpace(strings.Join(paragraphs[:len(paragraphs)-1], "\n\n"))
+	return body, footers
+}
+
+func isBreakingKey(key string) bool {
+	return key == "BREAKING CHANGE" || key == "BREAKING-CHANGE"
+}
+
+// formatEntries writes the grouped entries and contributors as markdown
+func formatEntries(notes *ReleaseNotes) string {
+	var content strings.Builder
+
+	// Write breaking changes first, with their explanation
+	if len(notes.Breaking) > 0 {
+		content.WriteString("## Breaking Changes\n\n")
+		for i, entry := range notes.Breaking {
+			content.WriteString(fmt.Sprintf("- %s\n", notes.entryLine(entry)))
+
+			// Indent the explanation so it belongs to the list item
+			if note := entry.BreakingNote(); note != "" {
+				content.WriteString("\n")
+				for _, line := range strings.Split(note, "\n") {
+					content.WriteString(strings.TrimRight("  "+line, " ") + "\n")
+				}
+				if i < len(notes.Breaking)-1 {
+					content.WriteString("\n")
 				}
-				content.WriteString(fmt.Sprintf("- %s%s\n", scope, entry.Description))
 			}
-			content.WriteString("\n")
 		}
+		content.WriteString("\n")
+	}
+
+	// Write entries by type
+	for _, group := range notes.Groups {
+		content.WriteString(fmt.Sprintf("## %s\n\n", group.Title))
+		for _, entry := range group.Entries {
+			content.WriteString(fmt.Sprintf("- %s\n", notes.entryLine(entry)))
+		}
+		content.WriteString("\n")
 	}
 
 	// Write contributors
 	content.WriteString("## Contributors\n\n")
-	authors := make(map[string]bool)
-	for _, entry := range entries {
-		authors[entry.Author] = true
-	}
-	for author := range authors {
+	for _, author := range notes.Contributors {
 		content.WriteString(fmt.Sprintf("- %s\n", author))
 	}
 
-	return content.String(), nil
+	// Link to the full list of commits
+	if notes.CompareURL != "" {
+		content.WriteString(fmt.Sprintf("\n**Full Changelog**: %s\n", notes.CompareURL))
+	}
+
+	return content.String()
 }
 
 func formatType(t string) string {
@@ -206,7 +368,7 @@ func formatType(t string) string {
 	return strings.Title(t)
 }
 
-func (g *Generator) writeChangelog(content string) error {
+func (g *Generator) writeChangelog(notes []*ReleaseNotes) error {
 	path := g.config.Release.Changelog.Path
 	if path == "" {
 		path = "CHANGELOG.md"
@@ -228,8 +390,15 @@ func (g *Generator) writeChangelog(content string) error {
 		existingContent = string(data)
 	}
 
+	// Add the releases to the existing contents in the file's format,
+	// replacing earlier sections for the same versions
+	merged, err := g.Merge(existingContent, notes...)
+	if err != nil {
+		return err
+	}
+
 	// Write new content
-	if err := os.WriteFile(path, []byte(content+"\n\n"+existingContent), 0644); err != nil {
+	if err := os.WriteFile(path, []byte(merged), 0644); err != nil {
 		return fmt.Errorf("failed to write changelog: %w", err)
 	}
 
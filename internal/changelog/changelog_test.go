@@ -0,0 +1,395 @@
+package changelog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"goreleaser-helper/internal/config"
+	"goreleaser-helper/internal/git"
+)
+
+// fakeRepository is a linear history for testing the generator without git
+type fakeRepository struct {
+	commits []git.Commit      // Newest first
+	tags    map[string]string // Tag name to commit hash
+}
+
+// newFakeRepository creates a history from the commit messages, oldest
+// first, with commits named c0, c1, ... one day apart
+func newFakeRepository(messages ...string) *fakeRepository {
+	repo := &fakeRepository{tags: make(map[string]string)}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, message := range messages {
+		commit := git.Commit{
+			Hash:    fmt.Sprintf("c%d", i),
+			Author:  "Jane Doe",
+			Date:    start.AddDate(0, 0, i),
+			Message: message,
+		}
+		repo.commits = append([]git.Commit{commit}, repo.commits...)
+	}
+	return repo
+}
+
+func (r *fakeRepository) tag(name, hash string) *fakeRepository {
+	r.tags[name] = hash
+	return r
+}
+
+// index returns the position of the commit ref points to
+func (r *fakeRepository) index(ref string) (int, error) {
+	if ref == "" || ref == "HEAD" {
+		return 0, nil
+	}
+	hash := ref
+	if tagged, ok := r.tags[ref]; ok {
+		hash = tagged
+	}
+	for i, commit := range r.commits {
+		if commit.Hash == hash {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown revision %s", ref)
+}
+
+func (r *fakeRepository) Commits(from, to string) ([]git.Commit, error) {
+	end, err := r.index(to)
+	if err != nil {
+		return nil, err
+	}
+	start := len(r.commits)
+	if from != "" {
+		if start, err = r.index(from); err != nil {
+			return nil, err
+		}
+	}
+	if start < end {
+		return nil, nil
+	}
+	return r.commits[end:start], nil
+}
+
+func (r *fakeRepository) LastTag(ref string) (string, error) {
+	i, err := r.index(ref)
+	if err != nil {
+		return "", err
+	}
+	for ; i < len(r.commits); i++ {
+		for name, hash := range r.tags {
+			if hash == r.commits[i].Hash {
+				return name, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+func (r *fakeRepository) Tags() ([]git.Tag, error) {
+	var tags []git.Tag
+	for name, hash := range r.tags {
+		tag := git.Tag{Name: name}
+		// Tags of commits outside the history are on another branch
+		if i, err := r.index(hash); err == nil {
+			tag.Date = r.commits[i].Date
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+func (r *fakeRepository) MergedTags(ref string) ([]string, error) {
+	end, err := r.index(ref)
+	if err != nil {
+		return nil, err
+	}
+	var merged []string
+	for name, hash := range r.tags {
+		if i, err := r.index(hash); err == nil && i >= end {
+			merged = append(merged, name)
+		}
+	}
+	return merged, nil
+}
+
+// testConfig loads the default configuration
+func testConfig(t *testing.T) *config.Config {
+	t.Helper()
+	return loadConfig(t, "")
+}
+
+// loadConfig loads a configuration file with the given content
+func loadConfig(t *testing.T, content string) *config.Config {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "goreleaser.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func TestParseCommitMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    Entry
+	}{
+		{
+			name:    "conventional subject",
+			message: "feat(api): add a | b filter",
+			want:    Entry{Type: "feat", Scope: "api", Description: "add a | b filter"},
+		},
+		{
+			name:    "breaking marker",
+			message: "refactor!: drop v1 endpoints",
+			want:    Entry{Type: "refactor", Breaking: true, Description: "drop v1 endpoints"},
+		},
+		{
+			name:    "not conventional",
+			message: "Update README",
+			want:    Entry{Type: "other", Description: "Update README"},
+		},
+		{
+			name:    "multi-line body without footers",
+			message: "fix: handle empty input\r\n\r\nThe parser crashed\r\non empty input.\r\n\r\nRefs are below: none",
+			want: Entry{
+				Type: "fix", Description: "handle empty input",
+				Body: "The parser crashed\non empty input.\n\nRefs are below: none",
+			},
+		},
+		{
+			name:    "breaking change footer",
+			message: "feat: new config\n\nBody.\n\nBREAKING CHANGE: the config\nformat changed\nRefs #12",
+			want: Entry{
+				Type: "feat", Breaking: true, Description: "new config", Body: "Body.",
+				Footers: []Footer{
+					{Key: "BREAKING CHANGE", Value: "the config\nformat changed"},
+					{Key: "Refs", Value: "12"},
+				},
+			},
+		},
+		{
+			name:    "co-authored-by trailer",
+			message: "fix: pair on it\n\nCo-authored-by: John Smith <john@example.com>",
+			want: Entry{
+				Type: "fix", Description: "pair on it",
+				Footers: []Footer{{Key: "Co-authored-by", Value: "John Smith <john@example.com>"}},
+			},
+		},
+		{
+			name:    "hyphenated breaking key",
+			message: "fix: rename flag\n\nBREAKING-CHANGE: --out is now --output",
+			want: Entry{
+				Type: "fix", Breaking: true, Description: "rename flag",
+				Footers: []Footer{{Key: "BREAKING-CHANGE", Value: "--out is now --output"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseCommitMessage(tt.message); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseCommitMessage() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		text        string
+		wantBody    string
+		wantFooters []Footer
+	}{
+		{name: "empty"},
+		{name: "body only", text: "Just a body.", wantBody: "Just a body."},
+		{
+			name:        "footers only",
+			text:        "Reviewed-by: Z\nRefs #1",
+			wantFooters: []Footer{{Key: "Reviewed-by", Value: "Z"}, {Key: "Refs", Value: "1"}},
+		},
+		{
+			name:        "continuation lines",
+			text:        "Body.\n\nBREAKING CHANGE: first\n  second\nAcked-by: X",
+			wantBody:    "Body.",
+			wantFooters: []Footer{{Key: "BREAKING CHANGE", Value: "first\n  second"}, {Key: "Acked-by", Value: "X"}},
+		},
+		{
+			name:     "footers must be the last paragraph",
+			text:     "Refs: #1\n\nMore text.",
+			wantBody: "Refs: #1\n\nMore text.",
+		},
+		{
+			name:     "keys cannot contain spaces",
+			text:     "Body.\n\nSee also: the docs",
+			wantBody: "Body.\n\nSee also: the docs",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, footers := parseBody(tt.text)
+			if body != tt.wantBody {
+				t.Errorf("body = %q, want %q", body, tt.wantBody)
+			}
+			if !reflect.DeepEqual(footers, tt.wantFooters) {
+				t.Errorf("footers = %+v, want %+v", footers, tt.wantFooters)
+			}
+		})
+	}
+}
+
+func TestNotesForRange(t *testing.T) {
+	repo := newFakeRepository(
+		"feat: first",
+		"fix: second",
+		"feat!: third\n\nCo-authored-by: John Smith <john@example.com>",
+		"docs: fourth",
+	).tag("v1.0.0", "c1").tag("v2.0.0-rc.1", "c2").tag("nightly", "c3").
+		// A release on a maintenance branch is not part of this history
+		tag("v1.5.0", "maintenance")
+
+	tests := []struct {
+		name     string
+		version  string
+		r        Range
+		wantFrom string
+		want     []string
+	}{
+		{name: "stable skips prereleases", version: "2.0.0", wantFrom: "v1.0.0", want: []string{"c2", "c3"}},
+		{name: "prerelease", version: "2.0.0-rc.2", wantFrom: "v2.0.0-rc.1", want: []string{"c3"}},
+		{name: "first release", version: "0.1.0", want: []string{"c0", "c1", "c2", "c3"}},
+		{name: "explicit range", version: "1.0.0", r: Range{To: "v1.0.0"}, wantFrom: "", want: []string{"c0", "c1"}},
+		{name: "unreleased", version: Unreleased, wantFrom: "v2.0.0-rc.1", want: []string{"c3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gen := NewGeneratorWithGit(testConfig(t), "owner/repo", repo)
+			notes, err := gen.NotesForRange(tt.version, tt.r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if notes.PreviousTag != tt.wantFrom {
+				t.Errorf("PreviousTag = %q, want %q", notes.PreviousTag, tt.wantFrom)
+			}
+
+			var got []string
+			for _, group := range notes.Groups {
+				for _, entry := range group.Entries {
+					got = append(got, entry.Hash)
+				}
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("entries = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHistory(t *testing.T) {
+	repo := newFakeRepository(
+		"feat: first",
+		"fix: second",
+		"feat: third",
+	).tag("v1.0.0", "c0").tag("1.0.0", "c0").tag("v1.1.0", "c2").tag("nightly", "c1")
+
+	gen := NewGeneratorWithGit(testConfig(t), "owner/repo", repo)
+	history, err := gen.History()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, notes := range history {
+		got = append(got, notes.Version+" from "+notes.PreviousTag)
+	}
+	want := []string{"1.1.0 from v1.0.0", "1.0.0 from "}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("history = %q, want %q", got, want)
+	}
+}
+
+func TestNextVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		repo    *fakeRepository
+		bump    string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "no tags",
+			repo: newFakeRepository("feat: first"),
+			bump: BumpAuto,
+			want: "0.1.0",
+		},
+		{
+			name: "non-version tags are ignored",
+			repo: newFakeRepository("feat: first", "fix: second").tag("v1.2.0", "c0").tag("nightly", "c1"),
+			bump: BumpAuto,
+			want: "1.2.1",
+		},
+		{
+			name: "unreachable tags are ignored",
+			repo: newFakeRepository("feat: first", "feat: second").tag("v1.0.0", "c0").tag("v9.0.0", "x"),
+			bump: BumpAuto,
+			want: "1.1.0",
+		},
+		{
+			name: "breaking change",
+			repo: newFakeRepository("feat: first", "fix!: second").tag("v1.0.0", "c0"),
+			bump: BumpAuto,
+			want: "2.0.0",
+		},
+		{
+			name: "prerelease continues",
+			repo: newFakeRepository("feat: first", "fix: second").tag("v1.1.0-rc.1", "c0"),
+			bump: BumpPrerelease,
+			want: "1.1.0-rc.2",
+		},
+		{
+			name:    "no commits since the tag",
+			repo:    newFakeRepository("feat: first").tag("v1.0.0", "c0"),
+			bump:    BumpAuto,
+			wantErr: "no commits since v1.0.0",
+		},
+		{
+			name:    "no commits in history",
+			repo:    newFakeRepository(),
+			bump:    BumpAuto,
+			wantErr: "no commits in history",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gen := NewGeneratorWithGit(testConfig(t), "", tt.repo)
+			got, err := gen.NextVersion(tt.bump)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("NextVersion(%s) = %s, want %s", tt.bump, got, tt.want)
+			}
+		})
+	}
+}
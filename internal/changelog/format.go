@@ -0,0 +1,308 @@
+package changelog
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Changelog formats
+const (
+	FormatMarkdown       = "markdown"
+	FormatKeepAChangelog = "keepachangelog"
+	FormatJSON           = "json"
+	FormatYAML           = "yaml"
+)
+
+// keepAChangelogPreface starts a new Keep a Changelog file
+const keepAChangelogPreface = `# Changelog
+
+All notable changes to this project will be documented in this file.
+
+The format is based on [Keep a Changelog](https://keepachangelog.com/en/1.1.0/),
+and this project adheres to [Semantic Versioning](https://semver.org/spec/v2.0.0.html).
+`
+
+// Formatter renders release notes in a changelog file format
+type Formatter interface {
+	// Format renders the notes of a single release
+	Format(notes *ReleaseNotes) (string, error)
+
+	// Merge adds a rendered release to the existing file contents, which
+	// are empty if the file does not exist yet
+	Merge(existing, release string) (string, error)
+}
+
+// NewFormatter returns the formatter for the given format name
+func NewFormatter(format string) (Formatter, error) {
+	switch format {
+	case "", FormatMarkdown:
+		return markdownFormatter{}, nil
+	case FormatKeepAChangelog:
+		return keepAChangelogFormatter{}, nil
+	case FormatJSON:
+		return jsonFormatter{}, nil
+	case FormatYAML:
+		return yamlFormatter{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported changelog format: %s", format)
+	}
+}
+
+// markdownFormatter writes one "# Changelog for <version>" section per
+// release, newest first
+type markdownFormatter struct{}
+
+func (markdownFormatter) Format(notes *ReleaseNotes) (string, error) {
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("# Changelog for %s\n\n", notes.Version))
+	content.WriteString(fmt.Sprintf("Release date: %s\n\n", notes.Date.Format("2006-01-02")))
+	content.WriteString(formatEntries(notes))
+	return content.String(), nil
+}
+
+// markdownHeading starts a release section of the markdown format
+var markdownHeading = regexp.MustCompile(`^# Changelog for `)
+
+func (markdownFormatter) Merge(existing, release string) (string, error) {
+	return mergeSections(existing, release, markdownHeading)
+}
+
+// keepAChangelogFormatter follows https://keepachangelog.com, mapping commit
+// types to its Added, Changed, Fixed and Security sections
+type keepAChangelogFormatter struct{}
+
+// keepAChangelogSections lists the sections in the order they are written
+var keepAChangelogSections = []string{"Added", "Changed", "Deprecated", "Removed", "Fixed", "Security"}
+
+func keepAChangelogSection(entry Entry) string {
+	switch entry.Type {
+	case "feat":
+		return "Added"
+	case "fix":
+		return "Fixed"
+	case "security":
+		return "Security"
+	case "deprecate", "deprecated":
+		return "Deprecated"
+	case "remove", "removed":
+		return "Removed"
+	default:
+		return "Changed"
+	}
+}
+
+func (keepAChangelogFormatter) Format(notes *ReleaseNotes) (string, error) {
+	sections := make(map[string][]string)
+	for _, entry := range notes.Breaking {
+		line := "**BREAKING:** " + notes.entryLine(entry)
+		if note := entry.BreakingNote(); note != "" {
+			line += ": " + strings.Join(strings.Fields(note), " ")
+		}
+		sections["Changed"] = append(sections["Changed"], line)
+	}
+	for _, group := range notes.Groups {
+		for _, entry := range group.Entries {
+			// Already listed as a breaking change
+			if entry.Breaking {
+				continue
+			}
+			line := notes.entryLine(entry)
+			section := keepAChangelogSection(entry)
+			sections[section] = append(sections[section], line)
+		}
+	}
+
+	var content strings.Builder
+	if sameVersion(notes.Version, Unreleased) {
+		// Unreleased changes have no date
+		content.WriteString(fmt.Sprintf("## [%s]\n", Unreleased))
+	} else {
+		content.WriteString(fmt.Sprintf("## [%s] - %s\n", notes.Version, notes.Date.Format("2006-01-02")))
+	}
+	for _, section := range keepAChangelogSections {
+		if len(sections[section]) == 0 {
+			continue
+		}
+		content.WriteString(fmt.Sprintf("\n### %s\n\n", section))
+		for _, line := range sections[section] {
+			content.WriteString(fmt.Sprintf("- %s\n", line))
+		}
+	}
+
+	// Link the version heading to the commits since the previous release
+	if notes.CompareURL != "" {
+		content.WriteString(fmt.Sprintf("\n[%s]: %s\n", notes.Version, notes.CompareURL))
+	}
+	return content.String(), nil
+}
+
+// keepAChangelogHeading starts a release section of a Keep a Changelog
+// file, including the [Unreleased] section
+var keepAChangelogHeading = regexp.MustCompile(`^## \[`)
+
+// Merge adds the release to the file, starting a new file with the standard
+// preface
+func (keepAChangelogFormatter) Merge(existing, release string) (string, error) {
+	if strings.TrimSpace(existing) == "" {
+		existing = keepAChangelogPreface
+	}
+	return mergeSections(existing, release, keepAChangelogHeading)
+}
+
+// releaseData is the structured form of release notes written by the JSON
+// and YAML formatters
+type releaseData struct {
+	Version      string      `json:"version" yaml:"version"`
+	Date         string      `json:"date" yaml:"date"`
+	PreviousTag  string      `json:"previousTag,omitempty" yaml:"previousTag,omitempty"`
+	RepoURL      string      `json:"repoURL,omitempty" yaml:"repoURL,omitempty"`
+	CompareURL   string      `json:"compareURL,omitempty" yaml:"compareURL,omitempty"`
+	Breaking     []entryData `json:"breaking,omitempty" yaml:"breaking,omitempty"`
+	Groups       []groupData `json:"groups" yaml:"groups"`
+	Contributors []string    `json:"contributors" yaml:"contributors"`
+}
+
+type groupData struct {
+	Title   string      `json:"title" yaml:"title"`
+	Entries []entryData `json:"entries" yaml:"entries"`
+}
+
+type entryData struct {
+	Type         string   `json:"type" yaml:"type"`
+	Scope        string   `json:"scope,omitempty" yaml:"scope,omitempty"`
+	Description  string   `json:"description" yaml:"description"`
+	Breaking     bool     `json:"breaking,omitempty" yaml:"breaking,omitempty"`
+	BreakingNote string   `json:"breakingNote,omitempty" yaml:"breakingNote,omitempty"`
+	Hash         string   `json:"hash" yaml:"hash"`
+	URL          string   `json:"url,omitempty" yaml:"url,omitempty"`
+	Author       string   `json:"author" yaml:"author"`
+	CoAuthors    []string `json:"coAuthors,omitempty" yaml:"coAuthors,omitempty"`
+	Date         string   `json:"date" yaml:"date"`
+}
+
+func newReleaseData(notes *ReleaseNotes) releaseData {
+	data := releaseData{
+		Version:      notes.Version,
+		Date:         notes.Date.Format("2006-01-02"),
+		PreviousTag:  notes.PreviousTag,
+		RepoURL:      notes.RepoURL,
+		CompareURL:   notes.CompareURL,
+		Groups:       []groupData{},
+		Contributors: notes.Contributors,
+	}
+	for _, entry := range notes.Breaking {
+		data.Breaking = append(data.Breaking, newEntryData(notes, entry))
+	}
+	for _, group := range notes.Groups {
+		g := groupData{Title: group.Title}
+		for _, entry := range group.Entries {
+			g.Entries = append(g.Entries, newEntryData(notes, entry))
+		}
+		data.Groups = append(data.Groups, g)
+	}
+	if data.Contributors == nil {
+		data.Contributors = []string{}
+	}
+	return data
+}
+
+func newEntryData(notes *ReleaseNotes, entry Entry) entryData {
+	return entryData{
+		Type:         entry.Type,
+		Scope:        entry.Scope,
+		Description:  entry.Description,
+		Breaking:     entry.Breaking,
+		BreakingNote: entry.BreakingNote(),
+		Hash:         entry.Hash,
+		URL:          notes.commitURL(entry.Hash),
+		Author:       entry.Author,
+		CoAuthors:    entry.CoAuthors,
+		Date:         entry.Date.Format(time.RFC3339),
+	}
+}
+
+// mergeReleases adds release to the list of releases, replacing an earlier
+// entry for the same version, and sorts them like mergeSections
+func mergeReleases(releases []releaseData, release releaseData) []releaseData {
+	merged := []releaseData{release}
+	for _, r := range releases {
+		if !sameVersion(r.Version, release.Version) {
+			merged = append(merged, r)
+		}
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		return newerVersion(merged[i].Version, merged[j].Version)
+	})
+	return merged
+}
+
+// jsonFormatter writes the changelog file as a JSON array of releases,
+// newest first
+type jsonFormatter struct{}
+
+func (jsonFormatter) Format(notes *ReleaseNotes) (string, error) {
+	data, err := json.MarshalIndent(newReleaseData(notes), "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
+
+func (jsonFormatter) Merge(existing, release string) (string, error) {
+	var current releaseData
+	if err := json.Unmarshal([]byte(release), &current); err != nil {
+		return "", err
+	}
+
+	var releases []releaseData
+	if strings.TrimSpace(existing) != "" {
+		if err := json.Unmarshal([]byte(existing), &releases); err != nil {
+			return "", fmt.Errorf("existing changelog is not a JSON list of releases: %w", err)
+		}
+	}
+
+	data, err := json.MarshalIndent(mergeReleases(releases, current), "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
+
+// yamlFormatter writes the changelog file as a YAML list of releases,
+// newest first
+type yamlFormatter struct{}
+
+func (yamlFormatter) Format(notes *ReleaseNotes) (string, error) {
+	data, err := yaml.Marshal(newReleaseData(notes))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func (yamlFormatter) Merge(existing, release string) (string, error) {
+	var current releaseData
+	if err := yaml.Unmarshal([]byte(release), &current); err != nil {
+		return "", err
+	}
+
+	var releases []releaseData
+	if strings.TrimSpace(existing) != "" {
+		if err := yaml.Unmarshal([]byte(existing), &releases); err != nil {
+			return "", fmt.Errorf("existing changelog is not a YAML list of releases: %w", err)
+		}
+	}
+
+	data, err := yaml.Marshal(mergeReleases(releases, current))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,109 @@
+package changelog
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"goreleaser-helper/internal/config"
+)
+
+// groupRule decides which entries belong to a changelog group
+type groupRule struct {
+	Title  string
+	Types  []string
+	Regex  *regexp.Regexp // Matched against the commit subject
+	Order  int
+	Hidden bool
+}
+
+// matches reports whether the entry belongs to the group
+func (r groupRule) matches(entry Entry) bool {
+	for _, t := range r.Types {
+		if t == entry.Type {
+			return true
+		}
+	}
+	return r.Regex != nil && r.Regex.MatchString(entry.Subject())
+}
+
+// defaultTypes lists the built-in commit types in the order they are shown
+var defaultTypes = []string{"feat", "fix", "docs", "style", "refactor", "perf", "test", "build", "ci", "chore", "other"}
+
+// groupRules returns the configured groups sorted by order, or one group
+// per built-in commit type if none are configured
+func groupRules(cfg *config.Config) ([]groupRule, error) {
+	configured := cfg.Release.Changelog.Groups
+	if len(configured) == 0 {
+		rules := make([]groupRule, 0, len(defaultTypes))
+		for _, t := range defaultTypes {
+			rules = append(rules, groupRule{Title: formatType(t), Types: []string{t}})
+		}
+		return rules, nil
+	}
+
+	rules := make([]groupRule, 0, len(configured))
+	for _, group := range configured {
+		rule := groupRule{
+			Title:  group.Title,
+			Types:  group.Types,
+			Order:  group.Order,
+			Hidden: group.Hidden,
+		}
+		if group.Regex != "" {
+			re, err := regexp.Compile(group.Regex)
+			if err != nil {
+				return nil, fmt.Errorf("invalid regex for changelog group %q: %w", group.Title, err)
+			}
+			rule.Regex = re
+		}
+		rules = append(rules, rule)
+	}
+
+	// Groups without an explicit order have order 0, so a negative order
+	// moves a group before them and a positive one after them. Groups with
+	// the same order keep their position.
+	sort.SliceStable(rules, func(i, j int) bool {
+		return rules[i].Order < rules[j].Order
+	})
+
+	return rules, nil
+}
+
+// groupEntries assigns every entry to the first matching group. Entries
+// that match no group fall back to the group for "other" commits. Hidden
+// and empty groups are left out.
+func groupEntries(rules []groupRule, entries []Entry) []Group {
+	grouped := make([][]Entry, len(rules))
+	for _, entry := range entries {
+		index := -1
+		for i, rule := range rules {
+			if rule.matches(entry) {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			other := entry
+			other.Type = "other"
+			for i, rule := range rules {
+				if rule.matches(other) {
+					index = i
+					break
+				}
+			}
+		}
+		if index >= 0 {
+			grouped[index] = append(grouped[index], entry)
+		}
+	}
+
+	var groups []Group
+	for i, rule := range rules {
+		if rule.Hidden || len(grouped[i]) == 0 {
+			continue
+		}
+		groups = append(groups, Group{Title: rule.Title, Types: rule.Types, Entries: grouped[i]})
+	}
+	return groups
+}
@@ -0,0 +1,66 @@
+package changelog
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGroupRulesOrder(t *testing.T) {
+	tests := []struct {
+		name   string
+		groups string
+		want   []string
+	}{
+		{
+			name: "position without order",
+			groups: `
+      - {title: Features, types: [feat]}
+      - {title: Fixes, types: [fix]}
+      - {title: Other, types: [other]}`,
+			want: []string{"Features", "Fixes", "Other"},
+		},
+		{
+			name: "negative order comes before unordered groups",
+			groups: `
+      - {title: Features, types: [feat]}
+      - {title: Fixes, types: [fix]}
+      - {title: Security, types: [security], order: -1}`,
+			want: []string{"Security", "Features", "Fixes"},
+		},
+		{
+			name: "positive order comes after unordered groups",
+			groups: `
+      - {title: Other, types: [other], order: 1}
+      - {title: Features, types: [feat]}
+      - {title: Fixes, types: [fix]}`,
+			want: []string{"Features", "Fixes", "Other"},
+		},
+		{
+			name: "equal orders keep their position",
+			groups: `
+      - {title: B, types: [b], order: 2}
+      - {title: A, types: [a], order: 1}
+      - {title: C, types: [c], order: 2}
+      - {title: D, types: [d]}`,
+			want: []string{"D", "A", "B", "C"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := loadConfig(t, "release:\n  changelog:\n    groups:"+tt.groups+"\n")
+			rules, err := groupRules(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var titles []string
+			for _, rule := range rules {
+				titles = append(titles, rule.Title)
+			}
+			if !reflect.DeepEqual(titles, tt.want) {
+				t.Errorf("groups = %q, want %q", titles, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package changelog
+
+import (
+	"fmt"
+	"sort"
+
+	"goreleaser-helper/internal/git"
+	"goreleaser-helper/internal/semver"
+)
+
+// Range selects the commits that release notes are generated from
+type Range struct {
+	From string // Exclusive start, the previous release tag when empty
+	To   string // Inclusive end, HEAD when empty
+}
+
+// NotesForRange collects the release notes for the given version from the
+// commits in the range
+func (g *Generator) NotesForRange(version string, r Range) (*ReleaseNotes, error) {
+	to := r.To
+	if to == "" {
+		to = "HEAD"
+	}
+
+	from := r.From
+	if from == "" {
+		var err error
+		if from, err = g.previousTag(version, to); err != nil {
+			return nil, fmt.Errorf("failed to get previous tag: %w", err)
+		}
+	}
+
+	entries, err := g.entries(from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commits: %w", err)
+	}
+
+	rules, err := groupRules(g.config)
+	if err != nil {
+		return nil, err
+	}
+
+	// Compare against the release tag, unless an explicit end was given
+	target := to
+	if to == "HEAD" && semver.IsValid(version) {
+		target = semver.TagName(version)
+	}
+
+	notes := newReleaseNotes(version, from, entries, rules)
+	notes.RepoURL = g.repoURL()
+	notes.CompareURL = g.compareURL(from, target)
+	return notes, nil
+}
+
+// previousTag returns the tag the notes for version start from: the
+// highest version tag below it that is reachable from ref, ignoring
+// prereleases for stable versions. If version is not a semantic version,
+// such as Unreleased, the highest version tag reachable from ref is used.
+func (g *Generator) previousTag(version, ref string) (string, error) {
+	current, err := semver.Parse(version)
+	if err != nil {
+		last, err := g.lastVersionTag(ref)
+		return last.Name, err
+	}
+
+	tags, err := g.reachableVersionTags(ref)
+	if err != nil {
+		return "", err
+	}
+
+	previous := ""
+	for _, tag := range tags {
+		if !tag.version.LessThan(current) {
+			break
+		}
+		if tag.version.IsPrerelease() && !current.IsPrerelease() {
+			continue
+		}
+		previous = tag.Name
+	}
+	return previous, nil
+}
+
+// versionTag is a tag whose name is a semantic version
+type versionTag struct {
+	git.Tag
+	version semver.Version
+}
+
+// versionTags returns the tags that are semantic versions, lowest first.
+// Other tags are ignored.
+func (g *Generator) versionTags() ([]versionTag, error) {
+	tags, err := g.git.Tags()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+
+	var result []versionTag
+	for _, tag := range tags {
+		if v, err := semver.Parse(tag.Name); err == nil {
+			result = append(result, versionTag{Tag: tag, version: v})
+		}
+	}
+
+	// Of tags for the same version, such as 1.0.0 and v1.0.0, the "v" tag
+	// sorts last so that it is the one picked as the previous or last tag
+	sort.SliceStable(result, func(i, j int) bool {
+		if c := result[i].version.Compare(result[j].version); c != 0 {
+			return c < 0
+		}
+		return result[i].Name != result[i].version.Tag() && result[j].Name == result[j].version.Tag()
+	})
+	return result, nil
+}
+
+// reachableVersionTags returns the version tags reachable from ref,
+// lowest first, leaving out tags on other branches
+func (g *Generator) reachableVersionTags(ref string) ([]versionTag, error) {
+	merged, err := g.git.MergedTags(ref)
+	if err != nil {
+		return nil, err
+	}
+	reachable := make(map[string]bool, len(merged))
+	for _, name := range merged {
+		reachable[name] = true
+	}
+
+	tags, err := g.versionTags()
+	if err != nil {
+		return nil, err
+	}
+	var result []versionTag
+	for _, tag := range tags {
+		if reachable[tag.Name] {
+			result = append(result, tag)
+		}
+	}
+	return result, nil
+}
+
+// lastVersionTag returns the highest version tag reachable from ref, or
+// the zero value if there is none. Tags that are not versions, such as
+// "nightly", are ignored.
+func (g *Generator) lastVersionTag(ref string) (versionTag, error) {
+	tags, err := g.reachableVersionTags(ref)
+	if err != nil || len(tags) == 0 {
+		return versionTag{}, err
+	}
+	return tags[len(tags)-1], nil
+}
+
+// History returns the release notes of every version tag, newest first,
+// dated with the tag's creation date. A version tagged twice, such as
+// v1.0.0 and 1.0.0, is listed once, preferring the "v" tag.
+func (g *Generator) History() ([]*ReleaseNotes, error) {
+	all, err := g.versionTags()
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []versionTag
+	seen := make(map[string]int) // Version to its index in tags
+	for _, tag := range all {
+		if i, ok := seen[tag.version.String()]; ok {
+			tags[i] = tag // The later tag is the "v" tag, if there is one
+			continue
+		}
+		seen[tag.version.String()] = len(tags)
+		tags = append(tags, tag)
+	}
+
+	var history []*ReleaseNotes
+	for i := len(tags) - 1; i >= 0; i-- {
+		notes, err := g.NotesForRange(tags[i].version.String(), Range{To: tags[i].Name})
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate notes for %s: %w", tags[i].Name, err)
+		}
+		notes.Date = tags[i].Date
+		history = append(history, notes)
+	}
+	return history, nil
+}
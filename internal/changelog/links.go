@@ -0,0 +1,49 @@
+package changelog
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// referenceRegexp matches "#123" pull request and issue references that are
+// not already part of a link
+var referenceRegexp = regexp.MustCompile(`(^|[\s(])#(\d+)\b`)
+
+// commitURL returns the link to a commit, or an empty string when the
+// repository is unknown
+func (n *ReleaseNotes) commitURL(hash string) string {
+	if n.RepoURL == "" || hash == "" {
+		return ""
+	}
+	return n.RepoURL + "/commit/" + hash
+}
+
+// linkReferences turns "#123" references into links. GitHub redirects
+// issue links to the pull request if the number belongs to one.
+func (n *ReleaseNotes) linkReferences(text string) string {
+	if n.RepoURL == "" {
+		return text
+	}
+	return referenceRegexp.ReplaceAllString(text, fmt.Sprintf("$1[#$2](%s/issues/$2)", n.RepoURL))
+}
+
+// entryLine renders an entry as the text of a markdown list item, with its
+// references and commit linked
+func (n *ReleaseNotes) entryLine(entry Entry) string {
+	line := n.linkReferences(entry.Description)
+	if entry.Scope != "" {
+		line = fmt.Sprintf("(%s) %s", entry.Scope, line)
+	}
+	if url := n.commitURL(entry.Hash); url != "" {
+		line += fmt.Sprintf(" ([%s](%s))", shortHash(entry.Hash), url)
+	}
+	return line
+}
+
+// shortHash abbreviates a commit hash like git's default
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
@@ -0,0 +1,55 @@
+package changelog
+
+import (
+	"sort"
+	"time"
+)
+
+// ReleaseNotes contains the structured notes for a single release
+type ReleaseNotes struct {
+	Version      string
+	Date         time.Time
+	PreviousTag  string
+	RepoURL      string  // Web URL of the repository, links are omitted when empty
+	CompareURL   string  // Link to the commits between PreviousTag and the release
+	Breaking     []Entry // Breaking changes, also listed in their group
+	Groups       []Group
+	Contributors []string
+}
+
+// Group contains the entries of a changelog section
+type Group struct {
+	Title   string
+	Types   []string
+	Entries []Entry
+}
+
+func newReleaseNotes(version, previousTag string, entries []Entry, rules []groupRule) *ReleaseNotes {
+	notes := &ReleaseNotes{
+		Version:     version,
+		Date:        time.Now(),
+		PreviousTag: previousTag,
+	}
+
+	// Breaking changes are always listed, even from hidden groups
+	for _, entry := range entries {
+		if entry.Breaking {
+			notes.Breaking = append(notes.Breaking, entry)
+		}
+	}
+	notes.Groups = groupEntries(rules, entries)
+
+	// Collect contributors, including co-authors
+	authors := make(map[string]bool)
+	for _, entry := range entries {
+		for _, author := range append([]string{entry.Author}, entry.CoAuthors...) {
+			if !authors[author] {
+				authors[author] = true
+				notes.Contributors = append(notes.Contributors, author)
+			}
+		}
+	}
+	sort.Strings(notes.Contributors)
+
+	return notes
+}
@@ -0,0 +1,135 @@
+package changelog
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"goreleaser-helper/internal/semver"
+)
+
+// Unreleased is the version of notes for commits that are not part of a
+// release yet
+const Unreleased = "Unreleased"
+
+// versionToken matches a word of a section heading, which is the version
+// of the section if semver.Parse accepts it
+var versionToken = regexp.MustCompile(`[^\s\[\]()]+`)
+
+// section is the part of a changelog file that belongs to one release
+type section struct {
+	version string
+	content string
+}
+
+// headingVersion returns the version of a line that matches heading, or
+// false if the line is not a heading or does not contain a version. An
+// "Unreleased" heading counts as a version.
+func headingVersion(line string, heading *regexp.Regexp) (string, bool) {
+	if !heading.MatchString(line) {
+		return "", false
+	}
+	for _, token := range versionToken.FindAllString(line, -1) {
+		if v, err := semver.Parse(token); err == nil {
+			return v.String(), true
+		}
+		if strings.EqualFold(token, Unreleased) {
+			return Unreleased, true
+		}
+	}
+	return "", false
+}
+
+// parseSections splits a changelog file into the preamble before the first
+// release and one section per release. A section starts at a line matching
+// heading that contains a version, and runs until the next one.
+func parseSections(content string, heading *regexp.Regexp) (string, []section) {
+	var preamble strings.Builder
+	var sections []section
+
+	for _, line := range strings.SplitAfter(content, "\n") {
+		if version, ok := headingVersion(strings.TrimRight(line, "\r\n"), heading); ok {
+			sections = append(sections, section{version: version})
+		}
+		if len(sections) == 0 {
+			preamble.WriteString(line)
+			continue
+		}
+		sections[len(sections)-1].content += line
+	}
+
+	return preamble.String(), sections
+}
+
+// mergeSections adds release to the changelog file, replacing the section of
+// the same version if there is one. The preamble is kept and the sections
+// are sorted by version, newest first, after the Unreleased section.
+func mergeSections(existing, release string, heading *regexp.Regexp) (string, error) {
+	_, added := parseSections(release, heading)
+	if len(added) != 1 {
+		return "", fmt.Errorf("rendered release does not start with a version heading")
+	}
+
+	preamble, sections := parseSections(existing, heading)
+	merged := added
+	for _, s := range sections {
+		if !sameVersion(s.version, added[0].version) {
+			merged = append(merged, s)
+		}
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		return newerVersion(merged[i].version, merged[j].version)
+	})
+
+	var content strings.Builder
+	if preamble = strings.TrimRight(preamble, "\n"); preamble != "" {
+		content.WriteString(preamble + "\n\n")
+	}
+	for i, s := range merged {
+		content.WriteString(strings.TrimRight(s.content, "\n") + "\n")
+		if i < len(merged)-1 {
+			content.WriteString("\n")
+		}
+	}
+	return content.String(), nil
+}
+
+// sameVersion compares two versions by precedence, falling back to the
+// strings if either cannot be parsed
+func sameVersion(a, b string) bool {
+	va, errA := semver.Parse(a)
+	vb, errB := semver.Parse(b)
+	if errA != nil || errB != nil {
+		return strings.EqualFold(a, b)
+	}
+	return va.Compare(vb) == 0
+}
+
+// newerVersion orders releases newest first: Unreleased, then versions by
+// precedence, then versions that cannot be parsed, which keep their order
+func newerVersion(a, b string) bool {
+	if strings.EqualFold(a, Unreleased) || strings.EqualFold(b, Unreleased) {
+		return !strings.EqualFold(b, Unreleased)
+	}
+	va, errA := semver.Parse(a)
+	vb, errB := semver.Parse(b)
+	if errA != nil || errB != nil {
+		return errA == nil && errB != nil
+	}
+	return vb.LessThan(va)
+}
+
+// headingRegexp returns the heading that starts a release section in
+// content rendered by a template: a markdown heading of the same level as
+// the first line, which must contain a version
+func headingRegexp(release string) (*regexp.Regexp, error) {
+	first, _, _ := strings.Cut(strings.TrimLeft(release, "\n"), "\n")
+	level := len(first) - len(strings.TrimLeft(first, "#"))
+	heading := regexp.MustCompile(fmt.Sprintf(`^#{%d}\s`, level))
+	if _, ok := headingVersion(first, heading); level == 0 || !ok {
+		return nil, fmt.Errorf("changelog template must start with a markdown heading containing the version")
+	}
+	return heading, nil
+}
@@ -0,0 +1,106 @@
+package changelog
+
+import "testing"
+
+func TestMergeSections(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string
+		release  string
+		want     string
+	}{
+		{
+			name:    "new file",
+			release: "# Changelog for 1.0.0\n\n- one\n",
+			want:    "# Changelog for 1.0.0\n\n- one\n",
+		},
+		{
+			name:     "newest first",
+			existing: "# Changelog for 1.0.0\n\n- one\n",
+			release:  "# Changelog for 1.1.0\n\n- two\n",
+			want:     "# Changelog for 1.1.0\n\n- two\n\n# Changelog for 1.0.0\n\n- one\n",
+		},
+		{
+			name:     "older release is inserted in order",
+			existing: "# Changelog for 2.0.0\n\n- three\n\n# Changelog for 1.0.0\n\n- one\n",
+			release:  "# Changelog for 1.1.0\n\n- two\n",
+			want:     "# Changelog for 2.0.0\n\n- three\n\n# Changelog for 1.1.0\n\n- two\n\n# Changelog for 1.0.0\n\n- one\n",
+		},
+		{
+			name:     "same version is replaced",
+			existing: "Preamble\n\n# Changelog for v1.0.0\n\n- old\n",
+			release:  "# Changelog for 1.0.0\n\n- new\n",
+			want:     "Preamble\n\n# Changelog for 1.0.0\n\n- new\n",
+		},
+		{
+			name:     "prerelease precedence",
+			existing: "# Changelog for 1.0.0-rc.10\n\n- rc10\n",
+			release:  "# Changelog for 1.0.0-rc.9\n\n- rc9\n",
+			want:     "# Changelog for 1.0.0-rc.10\n\n- rc10\n\n# Changelog for 1.0.0-rc.9\n\n- rc9\n",
+		},
+		{
+			name:     "unreleased comes first",
+			existing: "# Changelog for 1.0.0\n\n- one\n",
+			release:  "# Changelog for Unreleased\n\n- two\n",
+			want:     "# Changelog for Unreleased\n\n- two\n\n# Changelog for 1.0.0\n\n- one\n",
+		},
+		{
+			name:     "unreleased is replaced",
+			existing: "# Changelog for Unreleased\n\n- old\n\n# Changelog for 1.0.0\n\n- one\n",
+			release:  "# Changelog for Unreleased\n\n- new\n",
+			want:     "# Changelog for Unreleased\n\n- new\n\n# Changelog for 1.0.0\n\n- one\n",
+		},
+		{
+			name:     "release after unreleased",
+			existing: "# Changelog for Unreleased\n\n- two\n\n# Changelog for 1.0.0\n\n- one\n",
+			release:  "# Changelog for 1.1.0\n\n- two\n",
+			want:     "# Changelog for Unreleased\n\n- two\n\n# Changelog for 1.1.0\n\n- two\n\n# Changelog for 1.0.0\n\n- one\n",
+		},
+		{
+			name:     "headings without a version stay in the section",
+			existing: "# Changelog for 1.0.0\n\n# Changelog for the docs\n",
+			release:  "# Changelog for 1.0.1\n\n- fix\n",
+			want:     "# Changelog for 1.0.1\n\n- fix\n\n# Changelog for 1.0.0\n\n# Changelog for the docs\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mergeSections(tt.existing, tt.release, markdownHeading)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("mergeSections() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHeadingRegexp(t *testing.T) {
+	tests := []struct {
+		release string
+		line    string
+		want    string
+		wantErr bool
+	}{
+		{release: "## Release v1.2.0 (2024-01-01)\n", line: "## Release v1.1.0 (2023-12-01)", want: "1.1.0"},
+		{release: "# [1.2.0-rc.1]\n", line: "# [1.2.0]", want: "1.2.0"},
+		{release: "## Notes\n", wantErr: true},
+		{release: "Release 1.2.0\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		heading, err := headingRegexp(tt.release)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("headingRegexp(%q) error = %v, wantErr %t", tt.release, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if got, _ := headingVersion(tt.line, heading); got != tt.want {
+			t.Errorf("headingVersion(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
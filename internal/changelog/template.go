@@ -0,0 +1,100 @@
+package changelog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"goreleaser-helper/internal/semver"
+)
+
+// templateFormatter renders release notes with a user-defined text/template
+// file
+type templateFormatter struct {
+	tmpl *template.Template
+}
+
+// templateData is the data passed to changelog templates
+type templateData struct {
+	Version      string
+	Tag          string // Empty for unreleased changes
+	Date         string // Release date as YYYY-MM-DD
+	PreviousTag  string
+	RepoURL      string
+	CompareURL   string
+	Breaking     []Entry
+	Groups       []Group
+	Contributors []string
+	Notes        *ReleaseNotes // Full release notes, including the date as time.Time
+}
+
+// templateFuncs are available in changelog templates in addition to the
+// text/template builtins
+var templateFuncs = template.FuncMap{
+	"join":  strings.Join,
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"trim":  strings.TrimSpace,
+	"indent": func(spaces int, s string) string {
+		prefix := strings.Repeat(" ", spaces)
+		return prefix + strings.ReplaceAll(s, "\n", "\n"+prefix)
+	},
+}
+
+// NewTemplateFormatter returns a formatter that renders the template file
+// at path
+func NewTemplateFormatter(path string) (Formatter, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read changelog template: %w", err)
+	}
+
+	t, err := template.New(filepath.Base(path)).Funcs(templateFuncs).Option("missingkey=error").Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid changelog template %s: %w", path, err)
+	}
+
+	return templateFormatter{tmpl: t}, nil
+}
+
+func (f templateFormatter) Format(notes *ReleaseNotes) (string, error) {
+	// Unreleased changes have no tag yet
+	tag := ""
+	if semver.IsValid(notes.Version) {
+		tag = semver.TagName(notes.Version)
+	}
+
+	data := templateData{
+		Version:      notes.Version,
+		Tag:          tag,
+		Date:         notes.Date.Format("2006-01-02"),
+		PreviousTag:  notes.PreviousTag,
+		RepoURL:      notes.RepoURL,
+		CompareURL:   notes.CompareURL,
+		Breaking:     notes.Breaking,
+		Groups:       notes.Groups,
+		Contributors: notes.Contributors,
+		Notes:        notes,
+	}
+
+	var content strings.Builder
+	if err := f.tmpl.Execute(&content, data); err != nil {
+		return "", fmt.Errorf("failed to render changelog template: %w", err)
+	}
+	return content.String(), nil
+}
+
+// Merge replaces or inserts the release section when the template starts
+// with a heading containing the version, and prepends the release otherwise
+func (f templateFormatter) Merge(existing, release string) (string, error) {
+	heading, err := headingRegexp(release)
+	if err != nil {
+		if existing == "" {
+			return release, nil
+		}
+		return release + "\n\n" + existing, nil
+	}
+	return mergeSections(existing, release, heading)
+}
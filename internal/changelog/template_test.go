@@ -0,0 +1,37 @@
+package changelog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTemplateFormatterTag(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "changelog.tmpl")
+	if err := os.WriteFile(path, []byte("[{{.Tag}}] {{or .Tag .Version}}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := NewTemplateFormatter(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		version string
+		want    string
+	}{
+		{"1.0.0", "[v1.0.0] v1.0.0"},
+		{"v2.0.0-rc.1", "[v2.0.0-rc.1] v2.0.0-rc.1"},
+		{Unreleased, "[] Unreleased"},
+	}
+
+	for _, tt := range tests {
+		got, err := f.Format(&ReleaseNotes{Version: tt.version})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("Format(%s) = %q, want %q", tt.version, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package checksum
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"golang.org/x/crypto/blake2b"
+
+	"goreleaser-helper/internal/assets"
+)
+
+// Supported checksum algorithms
+var algorithms = map[string]func() hash.Hash{
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+	"blake2b": func() hash.Hash {
+		h, _ := blake2b.New512(nil)
+		return h
+	},
+}
+
+// IsSupported reports whether algorithm is a supported checksum algorithm
+func IsSupported(algorithm string) bool {
+	_, ok := algorithms[algorithm]
+	return ok
+}
+
+// Options contains the options for writing a checksums file
+type Options struct {
+	Algorithm string         // sha256, sha512 or blake2b
+	Path      string         // Path of the checksums file to write
+	Artifacts []assets.Asset // Artifacts to checksum
+}
+
+// Result is the outcome of verifying a single file
+type Result struct {
+	Name string
+	OK   bool
+	Err  error // Set when the file could not be read
+}
+
+// Write computes the checksum of every artifact and writes them to
+// opts.Path in the format used by sha256sum and friends, sorted by name
+func Write(opts Options) (assets.Asset, error) {
+	color.Blue("🔐 Computing %s checksums for %d artifacts...", opts.Algorithm, len(opts.Artifacts))
+
+	artifacts := append([]assets.Asset(nil), opts.Artifacts...)
+	sort.Slice(artifacts, func(i, j int) bool {
+		return artifacts[i].Name < artifacts[j].Name
+	})
+
+	var content strings.Builder
+	for _, artifact := range artifacts {
+		sum, err := File(artifact.Path, opts.Algorithm)
+		if err != nil {
+			return assets.Asset{}, fmt.Errorf("failed to checksum %s: %w", artifact.Name, err)
+		}
+		content.WriteString(fmt.Sprintf("%s  %s\n", sum, artifact.Name))
+	}
+
+	if err := os.MkdirAll(filepath.Dir(opts.Path), 0755); err != nil {
+		return assets.Asset{}, fmt.Errorf("failed to create checksums directory: %w", err)
+	}
+	if err := os.WriteFile(opts.Path, []byte(content.String()), 0644); err != nil {
+		return assets.Asset{}, fmt.Errorf("failed to write checksums file: %w", err)
+	}
+
+	color.Green("✅ Checksums written to %s", opts.Path)
+	return assets.Asset{Name: filepath.Base(opts.Path), Path: opts.Path}, nil
+}
+
+// File returns the hex-encoded checksum of the file at path
+func File(path, algorithm string) (string, error) {
+	newHash, ok := algorithms[algorithm]
+	if !ok {
+		return "", fmt.Errorf("unsupported checksum algorithm: %s", algorithm)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := newHash()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Verify checks the files listed in the checksums file at path against
+// their recorded checksums. Files are resolved relative to dir. If
+// algorithm is empty it is inferred from the checksum length, which only
+// works for sha256: sha512 and blake2b checksums are both 128 characters.
+func Verify(path, dir, algorithm string) ([]Result, error) {
+	if algorithm != "" && !IsSupported(algorithm) {
+		return nil, fmt.Errorf("unsupported checksum algorithm: %s", algorithm)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open checksums file: %w", err)
+	}
+	defer f.Close()
+
+	var results []Result
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		// Lines are "<checksum>  <name>", with "*" marking binary mode
+		expected, name, ok := strings.Cut(text, " ")
+		if !ok {
+			return nil, fmt.Errorf("malformed checksum on line %d", line)
+		}
+		name = strings.TrimPrefix(strings.TrimLeft(name, " "), "*")
+
+		algo := algorithm
+		if algo == "" {
+			algo, err = detectAlgorithm(expected)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+		}
+
+		actual, err := File(filepath.Join(dir, name), algo)
+		if err != nil {
+			results = append(results, Result{Name: name, Err: err})
+			continue
+		}
+		results = append(results, Result{Name: name, OK: strings.EqualFold(actual, expected)})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read checksums file: %w", err)
+	}
+
+	return results, nil
+}
+
+func detectAlgorithm(sum string) (string, error) {
+	switch len(sum) {
+	case sha256.Size * 2:
+		return "sha256", nil
+	case sha512.Size * 2:
+		return "", fmt.Errorf("%d-character checksum may be sha512 or blake2b, specify the algorithm with --algorithm", len(sum))
+	}
+	return "", fmt.Errorf("cannot infer checksum algorithm from %d-character checksum", len(sum))
+}
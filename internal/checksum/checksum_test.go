@@ -0,0 +1,68 @@
+package checksum
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"goreleaser-helper/internal/assets"
+)
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name       string
+		written    string // Algorithm the checksums file is written with
+		algorithm  string // Algorithm passed to Verify
+		corrupt    bool
+		wantErr    string
+		wantResult bool
+	}{
+		{name: "sha256 is inferred", written: "sha256", wantResult: true},
+		{name: "sha512", written: "sha512", algorithm: "sha512", wantResult: true},
+		{name: "blake2b", written: "blake2b", algorithm: "blake2b", wantResult: true},
+		{name: "sha512 is not inferred", written: "sha512", wantErr: "specify the algorithm"},
+		{name: "blake2b is not inferred", written: "blake2b", wantErr: "specify the algorithm"},
+		{name: "wrong algorithm", written: "blake2b", algorithm: "sha512", wantResult: false},
+		{name: "modified file", written: "sha256", corrupt: true, wantResult: false},
+		{name: "unsupported algorithm", written: "sha256", algorithm: "md5", wantErr: "unsupported"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			artifact := filepath.Join(dir, "demo.tar.gz")
+			if err := os.WriteFile(artifact, []byte("archive"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			sums, err := Write(Options{
+				Algorithm: tt.written,
+				Path:      filepath.Join(dir, "checksums.txt"),
+				Artifacts: []assets.Asset{{Name: "demo.tar.gz", Path: artifact}},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.corrupt {
+				if err := os.WriteFile(artifact, []byte("modified"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			results, err := Verify(sums.Path, dir, tt.algorithm)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(results) != 1 || results[0].Name != "demo.tar.gz" || results[0].Err != nil || results[0].OK != tt.wantResult {
+				t.Errorf("results = %+v, want OK %t", results, tt.wantResult)
+			}
+		})
+	}
+}
@@ -6,9 +6,12 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
-	"text/template"
 
 	"gopkg.in/yaml.v3"
+
+	"goreleaser-helper/internal/checksum"
+	"goreleaser-helper/internal/semver"
+	"goreleaser-helper/internal/tmpl"
 )
 
 // Config represents the application configuration
@@ -34,24 +37,75 @@ type Config struct {
 		Env     map[string]string `yaml:"env"`
 		Before  []string          `yaml:"before"` // Commands to run before build
 		After   []string          `yaml:"after"`  // Commands to run after build
+		// Commands to run after each target is built; templates receive
+		// Version, OS, Arch and Path
+		PostBuild []string `yaml:"postBuild"`
 	} `yaml:"build"`
 
+	// Archive configuration
+	Archive struct {
+		NameTemplate    string `yaml:"nameTemplate"` // Archive name without extension
+		Format          string `yaml:"format"`       // tar.gz, zip or binary
+		FormatOverrides []struct {
+			OS     string `yaml:"os"`
+			Format string `yaml:"format"`
+		} `yaml:"formatOverrides"`
+		Files []string `yaml:"files"` // Glob patterns for extra files to include
+	} `yaml:"archive"`
+
+	// Checksum configuration
+	Checksum struct {
+		Name      string `yaml:"name"`      // File name, written to outputDir/<version>
+		Algorithm string `yaml:"algorithm"` // sha256, sha512 or blake2b
+	} `yaml:"checksum"`
+
+	// Versioning configuration, used to compute the next version
+	Versioning struct {
+		PreMajorBreaking string `yaml:"preMajorBreaking"` // Bump for breaking changes before 1.0.0: major or minor
+		PreMajorFeature  string `yaml:"preMajorFeature"`  // Bump for features before 1.0.0: minor or patch
+		PrereleaseID     string `yaml:"prereleaseID"`     // Identifier for prerelease bumps, e.g. rc
+	} `yaml:"versioning"`
+
 	// Release configuration
 	Release struct {
 		DefaultBranch string `yaml:"defaultBranch"`
+		Draft         bool   `yaml:"draft"`      // Leave the release as a draft
+		Prerelease    string `yaml:"prerelease"` // auto, true or false
+		Existing      string `yaml:"existing"`   // fail, keep or update an existing release for the tag
 		Changelog     struct {
-			Enabled bool   `yaml:"enabled"`
-			Path    string `yaml:"path"`
-			Format  string `yaml:"format"` // markdown, json, etc.
+			Enabled  bool   `yaml:"enabled"`
+			Path     string `yaml:"path"`
+			Format   string `yaml:"format"`   // markdown, keepachangelog, json or yaml
+			Template string `yaml:"template"` // text/template file used instead of format
+			Groups   []struct {
+				Title  string   `yaml:"title"`
+				Types  []string `yaml:"types"`  // Commit types in this group
+				Regex  string   `yaml:"regex"`  // Regular expression matched against the commit subject
+				Order  int      `yaml:"order"`  // Groups are sorted by order (default 0), then by position
+				Hidden bool     `yaml:"hidden"` // Leave these commits out of the notes
+			} `yaml:"groups"`
 		} `yaml:"changelog"`
+		Tag struct {
+			Skip   bool   `yaml:"skip"`   // Do not create and push the release tag
+			Remote string `yaml:"remote"` // Remote to push the tag to
+			Sign   bool   `yaml:"sign"`   // Create a signed tag using git's signing configuration
+		} `yaml:"tag"`
+		Notes struct {
+			Header string `yaml:"header"` // Template prepended to the release body
+			Footer string `yaml:"footer"` // Template appended to the release body
+		} `yaml:"notes"`
 		Assets struct {
 			Include []string `yaml:"include"` // Glob patterns for files to include
 			Exclude []string `yaml:"exclude"` // Glob patterns for files to exclude
+			Strict  bool     `yaml:"strict"`  // Fail if an include pattern matches nothing
 		} `yaml:"assets"`
 		Sign struct {
-			Enabled bool   `yaml:"enabled"`
-			Key     string `yaml:"key"`
-			Pass    string `yaml:"pass"`
+			Enabled   bool   `yaml:"enabled"`
+			Type      string `yaml:"type"`      // gpg or ed25519, inferred from the key when empty
+			Key       string `yaml:"key"`       // Path to the private key file
+			Pass      string `yaml:"pass"`      // Deprecated: use passEnv instead
+			PassEnv   string `yaml:"passEnv"`   // Environment variable holding the key passphrase
+			Artifacts string `yaml:"artifacts"` // checksum or all
 		} `yaml:"sign"`
 	} `yaml:"release"`
 
@@ -59,6 +113,10 @@ type Config struct {
 	GitHub struct {
 		DefaultRepo string   `yaml:"defaultRepo"`
 		TokenEnv    string   `yaml:"tokenEnv"`
+		URL         string   `yaml:"url"`         // Web URL used for changelog links, e.g. https://ghe.example.com
+		APIURL      string   `yaml:"apiURL"`      // REST API base URL, e.g. https://ghe.example.com/api/v3
+		UploadURL   string   `yaml:"uploadURL"`   // Asset upload base URL, e.g. https://ghe.example.com/api/uploads
+		MaxAttempts int      `yaml:"maxAttempts"` // Attempts per API call before giving up
 		Labels      []string `yaml:"labels"`
 		Milestones  []string `yaml:"milestones"`
 		Teams       []string `yaml:"teams"`
@@ -120,18 +178,87 @@ func setDefaults(config *Config) {
 		}
 	}
 
+	// Archive defaults
+	if config.Archive.NameTemplate == "" {
+		config.Archive.NameTemplate = "{{.ProjectName}}_{{.Version}}_{{.Os}}_{{.Arch}}"
+	}
+	if config.Archive.Format == "" {
+		config.Archive.Format = "tar.gz"
+		if len(config.Archive.FormatOverrides) == 0 {
+			config.Archive.FormatOverrides = []struct {
+				OS     string `yaml:"os"`
+				Format string `yaml:"format"`
+			}{
+				{OS: "windows", Format: "zip"},
+			}
+		}
+	}
+	if config.Archive.Files == nil {
+		config.Archive.Files = []string{"LICENSE*", "README*"}
+	}
+
+	// Checksum defaults
+	if config.Checksum.Name == "" {
+		config.Checksum.Name = "checksums.txt"
+	}
+	if config.Checksum.Algorithm == "" {
+		config.Checksum.Algorithm = "sha256"
+	}
+
+	// Versioning defaults
+	if config.Versioning.PreMajorBreaking == "" {
+		config.Versioning.PreMajorBreaking = "minor"
+	}
+	if config.Versioning.PreMajorFeature == "" {
+		config.Versioning.PreMajorFeature = "minor"
+	}
+	if config.Versioning.PrereleaseID == "" {
+		config.Versioning.PrereleaseID = "rc"
+	}
+
 	// Release defaults
 	if config.Release.DefaultBranch == "" {
 		config.Release.DefaultBranch = "main"
 	}
+	if config.Release.Prerelease == "" {
+		config.Release.Prerelease = "auto"
+	}
+	if config.Release.Tag.Remote == "" {
+		config.Release.Tag.Remote = "origin"
+	}
+	if config.Release.Existing == "" {
+		config.Release.Existing = "keep"
+	}
+	if config.Release.Changelog.Path == "" {
+		config.Release.Changelog.Path = "CHANGELOG.md"
+	}
 	if config.Release.Changelog.Format == "" {
 		config.Release.Changelog.Format = "markdown"
 	}
 
+	if config.Release.Sign.PassEnv == "" {
+		config.Release.Sign.PassEnv = "SIGN_PASSPHRASE"
+	}
+	if config.Release.Sign.Artifacts == "" {
+		config.Release.Sign.Artifacts = "checksum"
+	}
+
 	// GitHub defaults
 	if config.GitHub.TokenEnv == "" {
 		config.GitHub.TokenEnv = "GITHUB_TOKEN"
 	}
+	if config.GitHub.URL == "" {
+		config.GitHub.URL = "https://github.com"
+	}
+	if config.GitHub.APIURL == "" {
+		config.GitHub.APIURL = "https://api.github.com"
+	}
+	if config.GitHub.UploadURL == "" {
+		config.GitHub.UploadURL = "https://uploads.github.com"
+	}
+	if config.GitHub.MaxAttempts == 0 {
+		config.GitHub.MaxAttempts = 5
+	}
 }
 
 // validateConfig validates the configuration values
@@ -142,7 +269,7 @@ func validateConfig(config *Config) error {
 	}
 
 	// Validate version format
-	if config.Project.Version != "" && !isValidVersion(config.Project.Version) {
+	if config.Project.Version != "" && !semver.IsValid(config.Project.Version) {
 		return fmt.Errorf("invalid version format: %s", config.Project.Version)
 	}
 
@@ -153,6 +280,72 @@ func validateConfig(config *Config) error {
 		}
 	}
 
+	// Validate archive formats
+	if !isValidArchiveFormat(config.Archive.Format) {
+		return fmt.Errorf("invalid archive format: %s", config.Archive.Format)
+	}
+	for _, override := range config.Archive.FormatOverrides {
+		if !isValidArchiveFormat(override.Format) {
+			return fmt.Errorf("invalid archive format for %s: %s", override.OS, override.Format)
+		}
+	}
+
+	// Validate checksum algorithm
+	if !checksum.IsSupported(config.Checksum.Algorithm) {
+		return fmt.Errorf("invalid checksum algorithm: %s", config.Checksum.Algorithm)
+	}
+
+	// Validate versioning rules
+	if b := config.Versioning.PreMajorBreaking; b != "major" && b != "minor" {
+		return fmt.Errorf("invalid versioning.preMajorBreaking: %s", b)
+	}
+	if f := config.Versioning.PreMajorFeature; f != "minor" && f != "patch" {
+		return fmt.Errorf("invalid versioning.preMajorFeature: %s", f)
+	}
+
+	// Validate prerelease mode
+	if p := config.Release.Prerelease; p != "auto" && p != "true" && p != "false" {
+		return fmt.Errorf("invalid prerelease mode: %s", p)
+	}
+
+	// Validate changelog format
+	if !isValidChangelogFormat(config.Release.Changelog.Format) {
+		return fmt.Errorf("invalid changelog format: %s", config.Release.Changelog.Format)
+	}
+
+	// Validate changelog groups
+	for _, group := range config.Release.Changelog.Groups {
+		if group.Title == "" && !group.Hidden {
+			return fmt.Errorf("changelog group without title")
+		}
+		if len(group.Types) == 0 && group.Regex == "" {
+			return fmt.Errorf("changelog group %q needs types or a regex", group.Title)
+		}
+		if group.Regex != "" {
+			if _, err := regexp.Compile(group.Regex); err != nil {
+				return fmt.Errorf("invalid regex for changelog group %q: %w", group.Title, err)
+			}
+		}
+	}
+
+	// Validate existing release mode
+	if e := config.Release.Existing; e != "fail" && e != "keep" && e != "update" {
+		return fmt.Errorf("invalid existing release mode: %s", e)
+	}
+
+	// Validate signing configuration
+	if config.Release.Sign.Enabled {
+		if config.Release.Sign.Key == "" {
+			return fmt.Errorf("release.sign.key is required when signing is enabled")
+		}
+		if t := config.Release.Sign.Type; t != "" && t != "gpg" && t != "ed25519" {
+			return fmt.Errorf("invalid signing key type: %s", t)
+		}
+		if a := config.Release.Sign.Artifacts; a != "checksum" && a != "all" {
+			return fmt.Errorf("invalid signing artifacts: %s", a)
+		}
+	}
+
 	// Validate GitHub configuration
 	if config.GitHub.DefaultRepo != "" && !isValidRepoURL(config.GitHub.DefaultRepo) {
 		return fmt.Errorf("invalid GitHub repository URL: %s", config.GitHub.DefaultRepo)
@@ -161,19 +354,41 @@ func validateConfig(config *Config) error {
 	return nil
 }
 
-// ProcessTemplate processes template strings in the configuration
-func (c *Config) ProcessTemplate(data interface{}) error {
-	// Process build ldflags
-	if c.Build.LdFlags != "" {
-		tmpl, err := template.New("ldflags").Parse(c.Build.LdFlags)
+// ProcessTemplate renders the templated configuration strings that do not
+// depend on a build target. Ldflags and hook commands are rendered per
+// target at build time with the same context.
+func (c *Config) ProcessTemplate(ctx tmpl.Context) error {
+	fields := map[string]*string{
+		"build.outputDir":        &c.Build.OutputDir,
+		"release.changelog.path": &c.Release.Changelog.Path,
+		"release.notes.header":   &c.Release.Notes.Header,
+		"release.notes.footer":   &c.Release.Notes.Footer,
+	}
+	for i := range c.Archive.Files {
+		fields[fmt.Sprintf("archive.files[%d]", i)] = &c.Archive.Files[i]
+	}
+	for i := range c.Release.Assets.Include {
+		fields[fmt.Sprintf("release.assets.include[%d]", i)] = &c.Release.Assets.Include[i]
+	}
+	for i := range c.Release.Assets.Exclude {
+		fields[fmt.Sprintf("release.assets.exclude[%d]", i)] = &c.Release.Assets.Exclude[i]
+	}
+
+	for name, field := range fields {
+		rendered, err := ctx.Apply(*field)
 		if err != nil {
-			return fmt.Errorf("invalid ldflags template: %w", err)
+			return fmt.Errorf("invalid %s template: %w", name, err)
 		}
-		var buf strings.Builder
-		if err := tmpl.Execute(&buf, data); err != nil {
-			return fmt.Errorf("failed to process ldflags template: %w", err)
+		*field = rendered
+	}
+
+	// Process build environment values
+	for k, v := range c.Build.Env {
+		rendered, err := ctx.Apply(v)
+		if err != nil {
+			return fmt.Errorf("invalid build.env.%s template: %w", k, err)
 		}
-		c.Build.LdFlags = buf.String()
+		c.Build.Env[k] = rendered
 	}
 
 	return nil
@@ -184,10 +399,6 @@ func isValidProjectName(name string) bool {
 	return regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9-]*$`).MatchString(name)
 }
 
-func isValidVersion(version string) bool {
-	return regexp.MustCompile(`^v?\d+\.\d+\.\d+(-[a-zA-Z0-9]+)?$`).MatchString(version)
-}
-
 func isValidPlatform(os, arch string) bool {
 	validOS := map[string]bool{
 		"darwin":  true,
@@ -201,10 +412,32 @@ func isValidPlatform(os, arch string) bool {
 	return validOS[os] && validArch[arch]
 }
 
+func isValidArchiveFormat(format string) bool {
+	return format == "tar.gz" || format == "zip" || format == "binary"
+}
+
+func isValidChangelogFormat(format string) bool {
+	return format == "markdown" || format == "keepachangelog" || format == "json" || format == "yaml"
+}
+
 func isValidRepoURL(url string) bool {
 	return regexp.MustCompile(`^github\.com/[a-zA-Z0-9-]+/[a-zA-Z0-9-]+$`).MatchString(url)
 }
 
+// RepoName returns repo as "owner/repo", stripping the scheme and host of
+// forms such as "github.com/owner/repo" or "https://github.com/owner/repo.git".
+// The host of links and API calls comes from the github settings instead.
+func RepoName(repo string) string {
+	repo = strings.TrimSuffix(strings.Trim(repo, "/"), ".git")
+	if _, rest, ok := strings.Cut(repo, "://"); ok {
+		repo = rest
+	}
+	if parts := strings.Split(repo, "/"); len(parts) > 2 {
+		return strings.Join(parts[len(parts)-2:], "/")
+	}
+	return repo
+}
+
 func getCurrentDir() string {
 	dir, err := os.Getwd()
 	if err != nil {
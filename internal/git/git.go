@@ -0,0 +1,117 @@
+package git
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// run executes git with the given arguments and returns its trimmed output.
+// Errors include git's stderr, which carries the useful message.
+func run(stdin string, args ...string) (string, error) {
+	return runIn("", stdin, args...)
+}
+
+// runIn is like run but executes git in dir, or the current directory when
+// dir is empty
+func runIn(dir, stdin string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if stdin != "" {
+		cmd.Stdin = strings.NewReader(stdin)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && stderr.Len() > 0 {
+			return "", fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(stderr.String()))
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// IsClean reports whether the worktree has no uncommitted changes, not
+// counting changes to the ignored paths
+func IsClean(ignore ...string) (bool, error) {
+	args := []string{"status", "--porcelain"}
+	if len(ignore) > 0 {
+		args = append(args, "--", ".")
+		for _, path := range ignore {
+			args = append(args, ":(exclude)"+path)
+		}
+	}
+	status, err := run("", args...)
+	if err != nil {
+		return false, err
+	}
+	return status == "", nil
+}
+
+// CommitFiles commits the current content of the given paths, leaving
+// other changes alone. It reports whether a commit was created, which is
+// not the case when the paths have no changes.
+func CommitFiles(message string, paths ...string) (bool, error) {
+	status, err := run("", append([]string{"status", "--porcelain", "--"}, paths...)...)
+	if err != nil {
+		return false, err
+	}
+	if status == "" {
+		return false, nil
+	}
+	if _, err := run("", append([]string{"add", "--"}, paths...)...); err != nil {
+		return false, err
+	}
+	if _, err := run(message, append([]string{"commit", "-q", "-F", "-", "--"}, paths...)...); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// CurrentBranch returns the checked out branch, or "HEAD" when detached
+func CurrentBranch() (string, error) {
+	return run("", "rev-parse", "--abbrev-ref", "HEAD")
+}
+
+// Head returns the commit hash of HEAD
+func Head() (string, error) {
+	return run("", "rev-parse", "HEAD")
+}
+
+// TagCommit returns the commit a tag points to, or an empty string if the
+// tag does not exist
+func TagCommit(tag string) (string, error) {
+	if _, err := run("", "rev-parse", "--verify", "--quiet", "refs/tags/"+tag); err != nil {
+		return "", nil
+	}
+	return run("", "rev-parse", tag+"^{commit}")
+}
+
+// CreateTag creates an annotated tag at HEAD with the given message,
+// signed with the user's configured key if sign is set
+func CreateTag(tag, message string, sign bool) error {
+	args := []string{"tag", "-a"}
+	if sign {
+		args = []string{"tag", "-s"}
+	}
+	// Keep markdown headings, which the default cleanup mode strips as comments
+	args = append(args, "--cleanup=whitespace", tag, "-F", "-")
+	_, err := run(message, args...)
+	return err
+}
+
+// PushTag pushes the tag to the given remote. If branch is set, HEAD is
+// pushed to it in the same atomic push.
+func PushTag(remote, tag, branch string) error {
+	args := []string{"push", "--atomic", remote, "refs/tags/" + tag}
+	if branch != "" {
+		args = append(args, "HEAD:refs/heads/"+branch)
+	}
+	_, err := run("", args...)
+	return err
+}
@@ -0,0 +1,205 @@
+package git
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// Commit is a single commit read from the repository history
+type Commit struct {
+	Hash        string
+	Author      string
+	AuthorEmail string
+	Date        time.Time // Author date
+	Message     string    // Full commit message, including trailers
+	Trailers    []Trailer
+	CoAuthors   []Person // Parsed from Co-authored-by trailers
+}
+
+// Trailer is a "Key: value" line at the end of a commit message, as parsed
+// by git
+type Trailer struct {
+	Key   string
+	Value string
+}
+
+// Person is a commit author or co-author
+type Person struct {
+	Name  string
+	Email string
+}
+
+// Repository reads commits and tags from a git repository. The changelog
+// depends on this interface so that it can work from a fake history.
+type Repository interface {
+	// Commits returns the commits reachable from to but not from from,
+	// newest first. An empty from returns the whole history of to.
+	Commits(from, to string) ([]Commit, error)
+
+	// LastTag returns the most recent tag reachable from ref, or an empty
+	// string if there is none
+	LastTag(ref string) (string, error)
+	// Tags returns all tags of the repository
+	Tags() ([]Tag, error)
+	// MergedTags returns the names of the tags reachable from ref
+	MergedTags(ref string) ([]string, error)
+}
+
+// Tag is a tag and the date it was created, which is the commit date for
+// lightweight tags
+type Tag struct {
+	Name string
+	Date time.Time
+}
+
+// ExecRepository is a Repository backed by the git command line
+type ExecRepository struct {
+	Dir string // Repository directory, the current directory when empty
+}
+
+// NewRepository returns a Repository for the repository in dir
+func NewRepository(dir string) *ExecRepository {
+	return &ExecRepository{Dir: dir}
+}
+
+// Fields of a commit record are separated with US and trailers with RS.
+// Records are separated with NUL by -z. None of them can appear in commit
+// messages, and the message comes last so it is never split.
+const (
+	fieldSeparator   = "\x1f"
+	trailerSeparator = "\x1e"
+	logFormat        = "%H%x1f%an%x1f%ae%x1f%ad%x1f%(trailers:unfold,separator=%x1e)%x1f%B"
+)
+
+// Commits implements Repository
+func (r *ExecRepository) Commits(from, to string) ([]Commit, error) {
+	if to == "" {
+		to = "HEAD"
+	}
+	rev := to
+	if from != "" {
+		rev = from + ".." + to
+	}
+
+	output, err := runIn(r.Dir, "", "log", "-z", "--date=iso-strict", "--format="+logFormat, rev, "--")
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []Commit
+	for _, record := range strings.Split(output, "\x00") {
+		if strings.TrimSpace(record) == "" {
+			continue
+		}
+		commit, err := parseCommit(record)
+		if err != nil {
+			return nil, err
+		}
+		commits = append(commits, commit)
+	}
+
+	return commits, nil
+}
+
+// LastTag implements Repository
+func (r *ExecRepository) LastTag(ref string) (string, error) {
+	if ref == "" {
+		ref = "HEAD"
+	}
+	// git describe fails when no tag is reachable, with a message that
+	// depends on the locale, so check for reachable tags first
+	tags, err := r.MergedTags(ref)
+	if err != nil || len(tags) == 0 {
+		return "", err
+	}
+	return runIn(r.Dir, "", "describe", "--tags", "--abbrev=0", ref)
+}
+
+// Tags implements Repository
+func (r *ExecRepository) Tags() ([]Tag, error) {
+	output, err := runIn(r.Dir, "", "for-each-ref", "--format=%(refname:strip=2)%1f%(creatordate:iso-strict)", "refs/tags")
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []Tag
+	for _, line := range strings.Split(output, "\n") {
+		name, created, ok := strings.Cut(line, fieldSeparator)
+		if !ok {
+			continue
+		}
+		date, err := time.Parse(time.RFC3339, created)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date for tag %s: %w", name, err)
+		}
+		tags = append(tags, Tag{Name: name, Date: date})
+	}
+
+	return tags, nil
+}
+
+// MergedTags implements Repository
+func (r *ExecRepository) MergedTags(ref string) ([]string, error) {
+	if ref == "" {
+		ref = "HEAD"
+	}
+	output, err := runIn(r.Dir, "", "tag", "--merged", ref)
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []string
+	for _, line := range strings.Split(output, "\n") {
+		if line != "" {
+			tags = append(tags, line)
+		}
+	}
+	return tags, nil
+}
+
+// parseCommit parses a record written with logFormat
+func parseCommit(record string) (Commit, error) {
+	parts := strings.SplitN(strings.TrimLeft(record, "\n"), fieldSeparator, 6)
+	if len(parts) != 6 {
+		return Commit{}, fmt.Errorf("unexpected git log record: %q", record)
+	}
+
+	date, err := time.Parse(time.RFC3339, parts[3])
+	if err != nil {
+		return Commit{}, fmt.Errorf("invalid date for commit %s: %w", parts[0], err)
+	}
+
+	commit := Commit{
+		Hash:        parts[0],
+		Author:      parts[1],
+		AuthorEmail: parts[2],
+		Date:        date,
+		Message:     strings.TrimSpace(parts[5]),
+	}
+
+	for _, line := range strings.Split(parts[4], trailerSeparator) {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		trailer := Trailer{Key: strings.TrimSpace(key), Value: strings.TrimSpace(value)}
+		commit.Trailers = append(commit.Trailers, trailer)
+
+		if strings.EqualFold(trailer.Key, "Co-authored-by") {
+			commit.CoAuthors = append(commit.CoAuthors, parsePerson(trailer.Value))
+		}
+	}
+
+	return commit, nil
+}
+
+// parsePerson parses "Name <email>", keeping the value as the name if it is
+// not in that form
+func parsePerson(value string) Person {
+	if addr, err := mail.ParseAddress(value); err == nil && addr.Name != "" {
+		return Person{Name: addr.Name, Email: addr.Address}
+	}
+	return Person{Name: value}
+}
@@ -0,0 +1,164 @@
+package git
+
+import (
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// record builds a git log record in logFormat
+func record(hash, author, email, date string, trailers []string, message string) string {
+	return strings.Join([]string{hash, author, email, date, strings.Join(trailers, trailerSeparator), message}, fieldSeparator)
+}
+
+func TestParseCommit(t *testing.T) {
+	date := time.Date(2024, 3, 1, 12, 0, 0, 0, time.FixedZone("", 3600))
+
+	tests := []struct {
+		name    string
+		record  string
+		want    Commit
+		wantErr bool
+	}{
+		{
+			name:   "pipes in the subject",
+			record: record("abc", "Jane Doe", "jane@example.com", "2024-03-01T12:00:00+01:00", nil, "feat: support a | b | c\n"),
+			want: Commit{
+				Hash: "abc", Author: "Jane Doe", AuthorEmail: "jane@example.com", Date: date,
+				Message: "feat: support a | b | c",
+			},
+		},
+		{
+			name: "multi-line body",
+			record: record("abc", "Jane Doe", "jane@example.com", "2024-03-01T12:00:00+01:00", nil,
+				"fix: handle empty input\n\nThe parser crashed\non empty input.\n\nSecond paragraph.\n"),
+			want: Commit{
+				Hash: "abc", Author: "Jane Doe", AuthorEmail: "jane@example.com", Date: date,
+				Message: "fix: handle empty input\n\nThe parser crashed\non empty input.\n\nSecond paragraph.",
+			},
+		},
+		{
+			name: "co-authors",
+			record: record("abc", "Jane Doe", "jane@example.com", "2024-03-01T12:00:00+01:00",
+				[]string{"Co-authored-by: John Smith <john@example.com>", "co-authored-by: bot", "Refs: #12"},
+				"feat: pair on it\n\nCo-authored-by: John Smith <john@example.com>\nco-authored-by: bot\nRefs: #12\n"),
+			want: Commit{
+				Hash: "abc", Author: "Jane Doe", AuthorEmail: "jane@example.com", Date: date,
+				Message: "feat: pair on it\n\nCo-authored-by: John Smith <john@example.com>\nco-authored-by: bot\nRefs: #12",
+				Trailers: []Trailer{
+					{Key: "Co-authored-by", Value: "John Smith <john@example.com>"},
+					{Key: "co-authored-by", Value: "bot"},
+					{Key: "Refs", Value: "#12"},
+				},
+				CoAuthors: []Person{{Name: "John Smith", Email: "john@example.com"}, {Name: "bot"}},
+			},
+		},
+		{
+			name:    "missing fields",
+			record:  "abc" + fieldSeparator + "Jane Doe",
+			wantErr: true,
+		},
+		{
+			name:    "invalid date",
+			record:  record("abc", "Jane Doe", "jane@example.com", "yesterday", nil, "fix: x"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCommit(tt.record)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !got.Date.Equal(tt.want.Date) {
+				t.Errorf("Date = %s, want %s", got.Date, tt.want.Date)
+			}
+			got.Date = tt.want.Date
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseCommit() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// newTestRepository creates an empty repository with a fixed identity
+func newTestRepository(t *testing.T) *ExecRepository {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	t.Setenv("GIT_AUTHOR_NAME", "Jane Doe")
+	t.Setenv("GIT_AUTHOR_EMAIL", "jane@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Jane Doe")
+	t.Setenv("GIT_COMMITTER_EMAIL", "jane@example.com")
+	// Messages must not matter, so run git in a non-English locale
+	t.Setenv("LC_ALL", "de_DE.UTF-8")
+	t.Setenv("LANGUAGE", "de")
+
+	repo := NewRepository(t.TempDir())
+	gitIn(t, repo, "init", "-q")
+	return repo
+}
+
+func gitIn(t *testing.T, repo *ExecRepository, args ...string) {
+	t.Helper()
+	if _, err := runIn(repo.Dir, "", args...); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExecRepository(t *testing.T) {
+	repo := newTestRepository(t)
+	gitIn(t, repo, "commit", "-q", "--allow-empty", "-m", "feat: first")
+
+	if tag, err := repo.LastTag("HEAD"); err != nil || tag != "" {
+		t.Fatalf("LastTag() without tags = %q, %v", tag, err)
+	}
+
+	gitIn(t, repo, "tag", "v1.0.0")
+	gitIn(t, repo, "commit", "-q", "--allow-empty", "-m", "fix: a | b\n\nBody line\n\nCo-authored-by: John Smith <john@example.com>")
+	gitIn(t, repo, "tag", "nightly")
+
+	if tag, err := repo.LastTag(""); err != nil || tag != "nightly" {
+		t.Errorf("LastTag() = %q, %v, want nightly", tag, err)
+	}
+	if tag, err := repo.LastTag("v1.0.0"); err != nil || tag != "v1.0.0" {
+		t.Errorf("LastTag(v1.0.0) = %q, %v, want v1.0.0", tag, err)
+	}
+
+	merged, err := repo.MergedTags("v1.0.0")
+	if err != nil || !reflect.DeepEqual(merged, []string{"v1.0.0"}) {
+		t.Errorf("MergedTags(v1.0.0) = %q, %v", merged, err)
+	}
+
+	tags, err := repo.Tags()
+	if err != nil || len(tags) != 2 {
+		t.Fatalf("Tags() = %+v, %v", tags, err)
+	}
+
+	commits, err := repo.Commits("v1.0.0", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commits) != 1 {
+		t.Fatalf("Commits() returned %d commits, want 1", len(commits))
+	}
+	commit := commits[0]
+	if !strings.HasPrefix(commit.Message, "fix: a | b\n\nBody line") || commit.Author != "Jane Doe" {
+		t.Errorf("unexpected commit %+v", commit)
+	}
+	if !reflect.DeepEqual(commit.CoAuthors, []Person{{Name: "John Smith", Email: "john@example.com"}}) {
+		t.Errorf("CoAuthors = %+v", commit.CoAuthors)
+	}
+
+	all, err := repo.Commits("", "")
+	if err != nil || len(all) != 2 {
+		t.Errorf("Commits() = %d commits, %v, want 2", len(all), err)
+	}
+}
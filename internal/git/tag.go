@@ -0,0 +1,96 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// TagOptions contains the options for creating a release tag
+type TagOptions struct {
+	Tag     string
+	Message string // Tag annotation, usually the release notes
+	Branch  string // Branch HEAD must be on
+	Remote  string // Remote to push the tag to
+	Sign    bool   // Create a signed tag
+
+	// Files written by the release, such as the changelog. They may have
+	// uncommitted changes and are committed on Branch before tagging.
+	Files []string
+}
+
+// VerifyTaggable checks that the worktree is clean, apart from the release
+// files, and HEAD is on the release branch, so that the tag points at
+// exactly what was built
+func VerifyTaggable(opts TagOptions) error {
+	clean, err := IsClean(opts.Files...)
+	if err != nil {
+		return fmt.Errorf("failed to check worktree: %w", err)
+	}
+	if !clean {
+		return fmt.Errorf("worktree has uncommitted changes, commit or stash them before releasing")
+	}
+
+	branch, err := CurrentBranch()
+	if err != nil {
+		return fmt.Errorf("failed to get current branch: %w", err)
+	}
+	if branch != opts.Branch {
+		return fmt.Errorf("HEAD is on %s, releases must be tagged on %s", branch, opts.Branch)
+	}
+
+	return nil
+}
+
+// CreateAndPushTag commits the release files, creates the annotated release
+// tag at HEAD and pushes both. An existing tag is accepted if it already
+// points at HEAD, which makes reruns of a failed release possible.
+func CreateAndPushTag(opts TagOptions) error {
+	existing, err := TagCommit(opts.Tag)
+	if err != nil {
+		return fmt.Errorf("failed to look up tag %s: %w", opts.Tag, err)
+	}
+
+	// A rerun finds the files committed by the first run, so only commit
+	// them for a new tag
+	if existing == "" && len(opts.Files) > 0 {
+		committed, err := CommitFiles(fmt.Sprintf("chore(release): %s", opts.Tag), opts.Files...)
+		if err != nil {
+			return fmt.Errorf("failed to commit %s: %w", strings.Join(opts.Files, ", "), err)
+		}
+		if committed {
+			color.Blue("📝 Committed %s", strings.Join(opts.Files, ", "))
+		}
+	}
+
+	head, err := Head()
+	if err != nil {
+		return fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	switch existing {
+	case "":
+		color.Blue("🏷️  Creating tag %s...", opts.Tag)
+		if err := CreateTag(opts.Tag, opts.Message, opts.Sign); err != nil {
+			return fmt.Errorf("failed to create tag %s: %w", opts.Tag, err)
+		}
+	case head:
+		color.Yellow("🏷️  Tag %s already exists at HEAD", opts.Tag)
+	default:
+		return fmt.Errorf("tag %s already exists at %s, not at HEAD", opts.Tag, existing)
+	}
+
+	color.Blue("⬆️  Pushing tag %s to %s...", opts.Tag, opts.Remote)
+	// The release commit must reach the branch along with the tag
+	branch := ""
+	if len(opts.Files) > 0 {
+		branch = opts.Branch
+	}
+	if err := PushTag(opts.Remote, opts.Tag, branch); err != nil {
+		return fmt.Errorf("failed to push tag %s: %w", opts.Tag, err)
+	}
+
+	color.Green("✅ Tag %s pushed!", opts.Tag)
+	return nil
+}
@@ -0,0 +1,105 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newReleaseCheckout creates a repository on main with one commit and a
+// bare origin remote, and changes into it for the duration of the test
+func newReleaseCheckout(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	t.Setenv("GIT_AUTHOR_NAME", "Jane Doe")
+	t.Setenv("GIT_AUTHOR_EMAIL", "jane@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Jane Doe")
+	t.Setenv("GIT_COMMITTER_EMAIL", "jane@example.com")
+
+	dir := t.TempDir()
+	remote := filepath.Join(dir, "origin.git")
+	local := filepath.Join(dir, "local")
+	for _, args := range [][]string{
+		{"init", "-q", "--bare", remote},
+		{"init", "-q", "-b", "main", local},
+		{"-C", local, "remote", "add", "origin", remote},
+		{"-C", local, "commit", "-q", "--allow-empty", "-m", "feat: first"},
+	} {
+		if _, err := run("", args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(local); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+func writeChangelog(t *testing.T, content string) {
+	t.Helper()
+	if err := os.WriteFile("CHANGELOG.md", []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// release runs the tag steps of a release that writes the changelog
+func release(t *testing.T, tag, changelog string) {
+	t.Helper()
+	opts := TagOptions{Tag: tag, Message: "Release " + tag, Branch: "main", Remote: "origin", Files: []string{"CHANGELOG.md"}}
+
+	if err := VerifyTaggable(opts); err != nil {
+		t.Fatalf("VerifyTaggable(%s) = %v", tag, err)
+	}
+	writeChangelog(t, changelog)
+	if err := CreateAndPushTag(opts); err != nil {
+		t.Fatalf("CreateAndPushTag(%s) = %v", tag, err)
+	}
+
+	if clean, err := IsClean(); err != nil || !clean {
+		t.Errorf("worktree not clean after tagging %s: %t, %v", tag, clean, err)
+	}
+	// run trims the output
+	if got, err := run("", "show", tag+":CHANGELOG.md"); err != nil || got != strings.TrimSpace(changelog) {
+		t.Errorf("changelog in %s = %q, %v, want %q", tag, got, err, changelog)
+	}
+	head, _ := Head()
+	for _, ref := range []string{"refs/tags/" + tag + "^{commit}", "refs/heads/main"} {
+		if got, err := run("", "--git-dir", "../origin.git", "rev-parse", ref); err != nil || got != head {
+			t.Errorf("origin %s = %q, %v, want HEAD %s", ref, got, err, head)
+		}
+	}
+}
+
+func TestReleaseCommitsChangelog(t *testing.T) {
+	newReleaseCheckout(t)
+
+	release(t, "v1.0.0", "# v1.0.0\n")
+
+	// A rerun of the same release regenerates the same changelog and finds
+	// the tag at HEAD
+	release(t, "v1.0.0", "# v1.0.0\n")
+
+	// The next release starts from a clean worktree
+	if _, err := run("", "commit", "-q", "--allow-empty", "-m", "fix: second"); err != nil {
+		t.Fatal(err)
+	}
+	release(t, "v1.1.0", "# v1.1.0\n\n# v1.0.0\n")
+
+	// Changes to other files still block the release
+	if err := os.WriteFile("main.go", []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	opts := TagOptions{Tag: "v1.2.0", Branch: "main", Remote: "origin", Files: []string{"CHANGELOG.md"}}
+	if err := VerifyTaggable(opts); err == nil {
+		t.Error("VerifyTaggable() with an uncommitted main.go succeeded")
+	}
+}
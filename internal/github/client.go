@@ -0,0 +1,365 @@
+package github
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"goreleaser-helper/internal/config"
+)
+
+// Client is a minimal client for the GitHub releases API
+type Client struct {
+	BaseURL    string       // REST API base URL
+	UploadURL  string       // Asset upload base URL, used when a release has no upload_url
+	Token      string       // Token sent in the Authorization header
+	HTTPClient *http.Client // Client used for all requests
+	UserAgent  string
+	Logger     *log.Logger // Receives one line per request, discarded when nil
+
+	// MaxAttempts is the maximum number of attempts per operation,
+	// including the first one
+	MaxAttempts int
+
+	// sleep waits between attempts, replaceable so retries can be observed
+	sleep func(time.Duration)
+}
+
+// Release is a GitHub release
+type Release struct {
+	ID         int64   `json:"id"`
+	TagName    string  `json:"tag_name"`
+	Name       string  `json:"name"`
+	Body       string  `json:"body"`
+	Draft      bool    `json:"draft"`
+	Prerelease bool    `json:"prerelease"`
+	HTMLURL    string  `json:"html_url"`
+	UploadURL  string  `json:"upload_url"`
+	Assets     []Asset `json:"assets"`
+}
+
+// Asset is a file attached to a GitHub release
+type Asset struct {
+	ID                 int64  `json:"id"`
+	Name               string `json:"name"`
+	Size               int64  `json:"size"`
+	State              string `json:"state"`
+	Digest             string `json:"digest"` // e.g. "sha256:<hex>", not set on older servers
+	BrowserDownloadURL string `json:"browser_download_url"`
+}
+
+// ReleaseRequest is the request body for creating or updating a release
+type ReleaseRequest struct {
+	TagName    string `json:"tag_name"`
+	Name       string `json:"name"`
+	Body       string `json:"body"`
+	Draft      bool   `json:"draft"`
+	Prerelease bool   `json:"prerelease"`
+}
+
+// APIError is returned for responses with an unexpected status code
+type APIError struct {
+	StatusCode int
+	Message    string
+	Body       string
+	Header     http.Header
+}
+
+func (e *APIError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("GitHub API error (%d): %s", e.StatusCode, e.Message)
+	}
+	if e.Body != "" {
+		return fmt.Sprintf("GitHub API error (%d): %s", e.StatusCode, e.Body)
+	}
+	return fmt.Sprintf("GitHub API error (%d): %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// NewClient creates a client for the GitHub server configured in cfg
+func NewClient(cfg *config.Config, token string) *Client {
+	return &Client{
+		BaseURL:     cfg.GitHub.APIURL,
+		UploadURL:   cfg.GitHub.UploadURL,
+		Token:       token,
+		HTTPClient:  &http.Client{Timeout: 10 * time.Minute},
+		UserAgent:   "goreleaser-helper",
+		MaxAttempts: cfg.GitHub.MaxAttempts,
+	}
+}
+
+// CreateRelease creates a new release
+func (c *Client) CreateRelease(owner, repo string, release ReleaseRequest) (*Release, error) {
+	var result Release
+	err := c.doJSON("POST", c.releasesURL(owner, repo), release, http.StatusCreated, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetRelease returns the release with the given ID
+func (c *Client) GetRelease(owner, repo string, id int64) (*Release, error) {
+	var result Release
+	err := c.doJSON("GET", fmt.Sprintf("%s/%d", c.releasesURL(owner, repo), id), nil, http.StatusOK, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetReleaseByTag returns the release for the given tag
+func (c *Client) GetReleaseByTag(owner, repo, tag string) (*Release, error) {
+	var result Release
+	endpoint := fmt.Sprintf("%s/tags/%s", c.releasesURL(owner, repo), url.PathEscape(tag))
+	if err := c.doJSON("GET", endpoint, nil, http.StatusOK, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListReleases returns all releases of the repository, including drafts
+// when the token has push access
+func (c *Client) ListReleases(owner, repo string) ([]Release, error) {
+	var result []Release
+	for page := 1; ; page++ {
+		var releases []Release
+		endpoint := fmt.Sprintf("%s?per_page=100&page=%d", c.releasesURL(owner, repo), page)
+		if err := c.doJSON("GET", endpoint, nil, http.StatusOK, &releases); err != nil {
+			return nil, err
+		}
+		result = append(result, releases...)
+		if len(releases) < 100 {
+			return result, nil
+		}
+	}
+}
+
+// FindReleaseByTag returns the release for the given tag, including draft
+// releases which the tags endpoint does not return. It returns nil if no
+// release exists for the tag.
+func (c *Client) FindReleaseByTag(owner, repo, tag string) (*Release, error) {
+	release, err := c.GetReleaseByTag(owner, repo, tag)
+	if err == nil {
+		return release, nil
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		return nil, err
+	}
+
+	// Drafts are only visible when listing releases
+	releases, err := c.ListReleases(owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	for i := range releases {
+		if releases[i].TagName == tag {
+			return &releases[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// UpdateRelease updates the release with the given ID
+func (c *Client) UpdateRelease(owner, repo string, id int64, release ReleaseRequest) (*Release, error) {
+	var result Release
+	err := c.doJSON("PATCH", fmt.Sprintf("%s/%d", c.releasesURL(owner, repo), id), release, http.StatusOK, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// PublishRelease turns a draft release into a published release
+func (c *Client) PublishRelease(owner, repo string, id int64) (*Release, error) {
+	var result Release
+	body := map[string]bool{"draft": false}
+	err := c.doJSON("PATCH", fmt.Sprintf("%s/%d", c.releasesURL(owner, repo), id), body, http.StatusOK, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteRelease deletes the release with the given ID
+func (c *Client) DeleteRelease(owner, repo string, id int64) error {
+	return c.doJSON("DELETE", fmt.Sprintf("%s/%d", c.releasesURL(owner, repo), id), nil, http.StatusNoContent, nil)
+}
+
+// UploadAsset uploads the file at path to the release under the given name.
+// Failed uploads are retried after deleting any partially created asset of
+// the same name, since GitHub rejects duplicate asset names.
+func (c *Client) UploadAsset(owner, repo string, release *Release, name, path string) (*Asset, error) {
+	fileInfo, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file info: %w", err)
+	}
+
+	// The upload URL is a URI template such as ".../assets{?name,label}".
+	// Fall back to the configured upload server if it is missing.
+	uploadURL := release.UploadURL
+	if i := strings.Index(uploadURL, "{"); i >= 0 {
+		uploadURL = uploadURL[:i]
+	}
+	if uploadURL == "" {
+		uploadURL = fmt.Sprintf("%s/repos/%s/%s/releases/%d/assets",
+			strings.TrimSuffix(c.UploadURL, "/"), owner, repo, release.ID)
+	}
+
+	var result Asset
+	err = c.retry(true, func(attempt int) error {
+		if attempt > 1 {
+			if err := c.deletePartialAsset(owner, repo, release.ID, name); err != nil {
+				return err
+			}
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open file %s: %w", path, err)
+		}
+		defer file.Close()
+
+		req, err := c.newRequest("POST", uploadURL+"?name="+url.QueryEscape(name), file)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/octet-stream")
+		req.ContentLength = fileInfo.Size()
+
+		return c.send(req, http.StatusCreated, &result)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// deletePartialAsset removes an asset left behind by a failed upload
+func (c *Client) deletePartialAsset(owner, repo string, releaseID int64, name string) error {
+	release, err := c.GetRelease(owner, repo, releaseID)
+	if err != nil {
+		return fmt.Errorf("failed to list assets: %w", err)
+	}
+	for _, asset := range release.Assets {
+		if asset.Name == name {
+			c.logf("deleting partial asset %s (%d)", name, asset.ID)
+			return c.DeleteAsset(owner, repo, asset.ID)
+		}
+	}
+	return nil
+}
+
+// DeleteAsset deletes the release asset with the given ID
+func (c *Client) DeleteAsset(owner, repo string, id int64) error {
+	return c.doJSON("DELETE", fmt.Sprintf("%s/assets/%d", c.releasesURL(owner, repo), id), nil, http.StatusNoContent, nil)
+}
+
+func (c *Client) releasesURL(owner, repo string) string {
+	return fmt.Sprintf("%s/repos/%s/%s/releases", strings.TrimSuffix(c.BaseURL, "/"), owner, repo)
+}
+
+// doJSON sends body encoded as JSON and decodes the response into result.
+// Idempotent methods are retried on server and network errors; every
+// method is retried when rate limited, since the request was not processed.
+func (c *Client) doJSON(method, endpoint string, body interface{}, expected int, result interface{}) error {
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+	}
+
+	idempotent := method != "POST"
+	return c.retry(idempotent, func(int) error {
+		var reader io.Reader
+		if data != nil {
+			reader = bytes.NewReader(data)
+		}
+
+		req, err := c.newRequest(method, endpoint, reader)
+		if err != nil {
+			return err
+		}
+		if data != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		return c.send(req, expected, result)
+	})
+}
+
+func (c *Client) newRequest(method, endpoint string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequest(method, endpoint, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Set headers
+	if c.Token != "" {
+		req.Header.Set("Authorization", "token "+c.Token)
+	}
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+
+	return req, nil
+}
+
+// send performs a single request and decodes the response into result,
+// returning an *APIError if the status code is not the expected one
+func (c *Client) send(req *http.Request, expected int, result interface{}) error {
+	c.logf("%s %s", req.Method, req.URL)
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	c.logf("%s %s: %d", req.Method, req.URL, resp.StatusCode)
+
+	if resp.StatusCode != expected {
+		apiErr := &APIError{StatusCode: resp.StatusCode, Body: string(body), Header: resp.Header}
+		var errData struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(body, &errData) == nil {
+			apiErr.Message = errData.Message
+		}
+		return apiErr
+	}
+
+	if result != nil && len(body) > 0 {
+		if err := json.Unmarshal(body, result); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (c *Client) logf(format string, args ...interface{}) {
+	if c.Logger != nil {
+		c.Logger.Printf(format, args...)
+	}
+}
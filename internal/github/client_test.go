@@ -0,0 +1,805 @@
+package github
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"goreleaser-helper/internal/assets"
+	"goreleaser-helper/internal/config"
+)
+
+// failure is a scripted response returned instead of handling a request
+type failure struct {
+	method  string
+	path    string
+	status  int
+	header  map[string]string
+	body    string
+	partial bool // For uploads: store the asset before failing
+}
+
+// fakeAPI is an in-memory fake of the GitHub releases API for owner/repo
+type fakeAPI struct {
+	t        *testing.T
+	server   *httptest.Server
+	mu       sync.Mutex
+	releases []*Release
+	nextID   int64
+	requests []string
+	failures []failure
+	partial  []Asset // Assets stored by failed uploads
+}
+
+func newFakeAPI(t *testing.T) *fakeAPI {
+	api := &fakeAPI{t: t, nextID: 1}
+	api.server = httptest.NewServer(http.HandlerFunc(api.handle))
+	t.Cleanup(api.server.Close)
+	return api
+}
+
+// client returns a client for the fake
+func (api *fakeAPI) client() *Client {
+	return &Client{
+		BaseURL:    api.server.URL + "/api",
+		UploadURL:  api.server.URL + "/uploads",
+		Token:      "secret",
+		HTTPClient: api.server.Client(),
+		UserAgent:  "test",
+	}
+}
+
+// addRelease stores a release as if it had been created earlier
+func (api *fakeAPI) addRelease(r Release) *Release {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	return api.store(r)
+}
+
+func (api *fakeAPI) store(r Release) *Release {
+	r.ID = api.nextID
+	api.nextID++
+	r.UploadURL = fmt.Sprintf("%s/uploads/repos/owner/repo/releases/%d/assets{?name,label}", api.server.URL, r.ID)
+	api.releases = append(api.releases, &r)
+	return &r
+}
+
+func (api *fakeAPI) release(id int64) *Release {
+	for _, r := range api.releases {
+		if r.ID == id {
+			return r
+		}
+	}
+	return nil
+}
+
+func (api *fakeAPI) handle(w http.ResponseWriter, req *http.Request) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	path := req.URL.Path
+	api.requests = append(api.requests, req.Method+" "+path)
+
+	if got := req.Header.Get("Authorization"); got != "token secret" {
+		api.t.Errorf("%s %s: Authorization = %q", req.Method, path, got)
+	}
+
+	for i, f := range api.failures {
+		if f.method == req.Method && f.path == path {
+			api.failures = append(api.failures[:i], api.failures[i+1:]...)
+			if f.partial {
+				if asset, ok := api.upload(req); ok {
+					api.partial = append(api.partial, asset)
+				}
+			}
+			for k, v := range f.header {
+				w.Header().Set(k, v)
+			}
+			w.WriteHeader(f.status)
+			io.WriteString(w, f.body)
+			return
+		}
+	}
+
+	const releases = "/api/repos/owner/repo/releases"
+	switch {
+	case req.Method == "POST" && path == releases:
+		var body ReleaseRequest
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		r := api.store(Release{TagName: body.TagName, Name: body.Name, Body: body.Body, Draft: body.Draft, Prerelease: body.Prerelease})
+		writeJSON(w, http.StatusCreated, r)
+
+	case req.Method == "GET" && path == releases:
+		list := []Release{}
+		if req.URL.Query().Get("page") == "1" {
+			for _, r := range api.releases {
+				list = append(list, *r)
+			}
+		}
+		writeJSON(w, http.StatusOK, list)
+
+	case req.Method == "GET" && strings.HasPrefix(path, releases+"/tags/"):
+		tag := strings.TrimPrefix(path, releases+"/tags/")
+		for _, r := range api.releases {
+			if r.TagName == tag && !r.Draft {
+				writeJSON(w, http.StatusOK, r)
+				return
+			}
+		}
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+
+	case req.Method == "DELETE" && strings.HasPrefix(path, releases+"/assets/"):
+		id, _ := strconv.ParseInt(strings.TrimPrefix(path, releases+"/assets/"), 10, 64)
+		for _, r := range api.releases {
+			for i, a := range r.Assets {
+				if a.ID == id {
+					r.Assets = append(r.Assets[:i], r.Assets[i+1:]...)
+					w.WriteHeader(http.StatusNoContent)
+					return
+				}
+			}
+		}
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+
+	case strings.HasPrefix(path, releases+"/"):
+		id, _ := strconv.ParseInt(strings.TrimPrefix(path, releases+"/"), 10, 64)
+		r := api.release(id)
+		if r == nil {
+			writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+			return
+		}
+		switch req.Method {
+		case "GET":
+			writeJSON(w, http.StatusOK, r)
+		case "PATCH":
+			// Decode into the release so that omitted fields are kept
+			if err := json.NewDecoder(req.Body).Decode(r); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			writeJSON(w, http.StatusOK, r)
+		case "DELETE":
+			for i := range api.releases {
+				if api.releases[i].ID == id {
+					api.releases = append(api.releases[:i], api.releases[i+1:]...)
+					break
+				}
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}
+
+	case req.Method == "POST" && strings.HasPrefix(path, "/uploads/repos/owner/repo/releases/"):
+		asset, ok := api.upload(req)
+		if !ok {
+			writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+			return
+		}
+		writeJSON(w, http.StatusCreated, asset)
+
+	default:
+		api.t.Errorf("unexpected request %s %s", req.Method, req.URL)
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+// upload stores the uploaded asset on its release
+func (api *fakeAPI) upload(req *http.Request) (Asset, bool) {
+	rest := strings.TrimPrefix(req.URL.Path, "/uploads/repos/owner/repo/releases/")
+	id, _ := strconv.ParseInt(strings.TrimSuffix(rest, "/assets"), 10, 64)
+	r := api.release(id)
+	if r == nil {
+		return Asset{}, false
+	}
+	data, _ := io.ReadAll(req.Body)
+	asset := Asset{ID: 100 + api.nextID, Name: req.URL.Query().Get("name"), Size: int64(len(data)), State: "uploaded"}
+	api.nextID++
+	r.Assets = append(r.Assets, asset)
+	return asset, true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeAsset creates a file to upload and returns its path
+func writeAsset(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "asset.tar.gz")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestClientReleases(t *testing.T) {
+	tests := []struct {
+		name         string
+		setup        func(api *fakeAPI)
+		run          func(t *testing.T, c *Client)
+		wantRequests []string
+		wantReleases int
+	}{
+		{
+			name: "create",
+			run: func(t *testing.T, c *Client) {
+				r, err := c.CreateRelease("owner", "repo", ReleaseRequest{TagName: "v1.0.0", Name: "Release v1.0.0", Draft: true})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if r.ID == 0 || r.TagName != "v1.0.0" || !r.Draft {
+					t.Errorf("unexpected release %+v", r)
+				}
+			},
+			wantRequests: []string{"POST /api/repos/owner/repo/releases"},
+			wantReleases: 1,
+		},
+		{
+			name:  "get",
+			setup: func(api *fakeAPI) { api.addRelease(Release{TagName: "v1.0.0"}) },
+			run: func(t *testing.T, c *Client) {
+				r, err := c.GetRelease("owner", "repo", 1)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if r.TagName != "v1.0.0" {
+					t.Errorf("TagName = %q", r.TagName)
+				}
+			},
+			wantRequests: []string{"GET /api/repos/owner/repo/releases/1"},
+			wantReleases: 1,
+		},
+		{
+			name:  "get by tag",
+			setup: func(api *fakeAPI) { api.addRelease(Release{TagName: "v1.0.0"}) },
+			run: func(t *testing.T, c *Client) {
+				r, err := c.GetReleaseByTag("owner", "repo", "v1.0.0")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if r.ID != 1 {
+					t.Errorf("ID = %d", r.ID)
+				}
+			},
+			wantRequests: []string{"GET /api/repos/owner/repo/releases/tags/v1.0.0"},
+			wantReleases: 1,
+		},
+		{
+			name:  "update",
+			setup: func(api *fakeAPI) { api.addRelease(Release{TagName: "v1.0.0", Body: "old"}) },
+			run: func(t *testing.T, c *Client) {
+				r, err := c.UpdateRelease("owner", "repo", 1, ReleaseRequest{TagName: "v1.0.0", Name: "Release v1.0.0", Body: "new"})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if r.Body != "new" {
+					t.Errorf("Body = %q", r.Body)
+				}
+			},
+			wantRequests: []string{"PATCH /api/repos/owner/repo/releases/1"},
+			wantReleases: 1,
+		},
+		{
+			name:  "delete",
+			setup: func(api *fakeAPI) { api.addRelease(Release{TagName: "v1.0.0"}) },
+			run: func(t *testing.T, c *Client) {
+				if err := c.DeleteRelease("owner", "repo", 1); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantRequests: []string{"DELETE /api/repos/owner/repo/releases/1"},
+			wantReleases: 0,
+		},
+		{
+			name: "delete asset",
+			setup: func(api *fakeAPI) {
+				api.addRelease(Release{TagName: "v1.0.0", Assets: []Asset{{ID: 7, Name: "a.zip"}}})
+			},
+			run: func(t *testing.T, c *Client) {
+				if err := c.DeleteAsset("owner", "repo", 7); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantRequests: []string{"DELETE /api/repos/owner/repo/releases/assets/7"},
+			wantReleases: 1,
+		},
+		{
+			name:  "publish",
+			setup: func(api *fakeAPI) { api.addRelease(Release{TagName: "v1.0.0", Body: "notes", Draft: true}) },
+			run: func(t *testing.T, c *Client) {
+				r, err := c.PublishRelease("owner", "repo", 1)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if r.Draft || r.Body != "notes" {
+					t.Errorf("unexpected release %+v", r)
+				}
+			},
+			wantRequests: []string{"PATCH /api/repos/owner/repo/releases/1"},
+			wantReleases: 1,
+		},
+		{
+			name:  "find published release",
+			setup: func(api *fakeAPI) { api.addRelease(Release{TagName: "v1.0.0"}) },
+			run: func(t *testing.T, c *Client) {
+				r, err := c.FindReleaseByTag("owner", "repo", "v1.0.0")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if r == nil || r.ID != 1 {
+					t.Errorf("release = %+v", r)
+				}
+			},
+			wantRequests: []string{"GET /api/repos/owner/repo/releases/tags/v1.0.0"},
+			wantReleases: 1,
+		},
+		{
+			name:  "find draft release",
+			setup: func(api *fakeAPI) { api.addRelease(Release{TagName: "v1.0.0", Draft: true}) },
+			run: func(t *testing.T, c *Client) {
+				r, err := c.FindReleaseByTag("owner", "repo", "v1.0.0")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if r == nil || !r.Draft {
+					t.Errorf("release = %+v", r)
+				}
+			},
+			wantRequests: []string{
+				"GET /api/repos/owner/repo/releases/tags/v1.0.0",
+				"GET /api/repos/owner/repo/releases",
+			},
+			wantReleases: 1,
+		},
+		{
+			name: "find missing release",
+			run: func(t *testing.T, c *Client) {
+				r, err := c.FindReleaseByTag("owner", "repo", "v1.0.0")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if r != nil {
+					t.Errorf("release = %+v, want nil", r)
+				}
+			},
+			wantRequests: []string{
+				"GET /api/repos/owner/repo/releases/tags/v1.0.0",
+				"GET /api/repos/owner/repo/releases",
+			},
+			wantReleases: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI(t)
+			if tt.setup != nil {
+				tt.setup(api)
+			}
+
+			tt.run(t, api.client())
+
+			if !reflect.DeepEqual(api.requests, tt.wantRequests) {
+				t.Errorf("requests = %q, want %q", api.requests, tt.wantRequests)
+			}
+			if len(api.releases) != tt.wantReleases {
+				t.Errorf("%d releases, want %d", len(api.releases), tt.wantReleases)
+			}
+		})
+	}
+}
+
+func TestUploadAsset(t *testing.T) {
+	tests := []struct {
+		name        string
+		uploadURL   func(r *Release) string
+		wantRequest string
+	}{
+		{
+			name:        "upload_url template is stripped",
+			uploadURL:   func(r *Release) string { return r.UploadURL },
+			wantRequest: "POST /uploads/repos/owner/repo/releases/1/assets",
+		},
+		{
+			name:        "configured upload server without upload_url",
+			uploadURL:   func(r *Release) string { return "" },
+			wantRequest: "POST /uploads/repos/owner/repo/releases/1/assets",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI(t)
+			release := api.addRelease(Release{TagName: "v1.0.0"})
+			local := *release
+			local.UploadURL = tt.uploadURL(release)
+
+			asset, err := api.client().UploadAsset("owner", "repo", &local, "demo_1.0.0.tar.gz", writeAsset(t, "archive"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if asset.Name != "demo_1.0.0.tar.gz" || asset.Size != int64(len("archive")) {
+				t.Errorf("unexpected asset %+v", asset)
+			}
+			if !reflect.DeepEqual(api.requests, []string{tt.wantRequest}) {
+				t.Errorf("requests = %q, want %q", api.requests, tt.wantRequest)
+			}
+		})
+	}
+}
+
+func TestAPIError(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		wantMessage string
+		wantError   string
+	}{
+		{
+			name:        "JSON message",
+			status:      http.StatusUnprocessableEntity,
+			body:        `{"message":"Validation Failed","errors":[{"code":"already_exists"}]}`,
+			wantMessage: "Validation Failed",
+			wantError:   "GitHub API error (422): Validation Failed",
+		},
+		{
+			name:      "plain text body",
+			status:    http.StatusBadRequest,
+			body:      "bad request",
+			wantError: "GitHub API error (400): bad request",
+		},
+		{
+			name:      "empty body",
+			status:    http.StatusUnauthorized,
+			wantError: "GitHub API error (401): Unauthorized",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI(t)
+			api.addRelease(Release{TagName: "v1.0.0"})
+			api.failures = []failure{{method: "GET", path: "/api/repos/owner/repo/releases/1", status: tt.status, body: tt.body}}
+
+			_, err := api.client().GetRelease("owner", "repo", 1)
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("error = %v, want *APIError", err)
+			}
+			if apiErr.StatusCode != tt.status || apiErr.Message != tt.wantMessage || apiErr.Body != tt.body {
+				t.Errorf("unexpected error %+v", apiErr)
+			}
+			if apiErr.Error() != tt.wantError {
+				t.Errorf("Error() = %q, want %q", apiErr.Error(), tt.wantError)
+			}
+		})
+	}
+}
+
+func TestRetry(t *testing.T) {
+	reset := strconv.FormatInt(time.Now().Add(10*time.Second).Unix(), 10)
+
+	tests := []struct {
+		name         string
+		failures     []failure
+		run          func(t *testing.T, c *Client, release *Release) error
+		wantErr      bool
+		wantRequests []string // Requests other than deletes of partial assets
+		wantDeletes  int      // Partial assets deleted before the retried upload
+		wantSleeps   func(t *testing.T, sleeps []time.Duration)
+	}{
+		{
+			name:     "5xx on idempotent call is retried",
+			failures: []failure{{method: "GET", path: "/api/repos/owner/repo/releases/1", status: http.StatusBadGateway}},
+			run: func(t *testing.T, c *Client, release *Release) error {
+				_, err := c.GetRelease("owner", "repo", release.ID)
+				return err
+			},
+			wantRequests: []string{
+				"GET /api/repos/owner/repo/releases/1",
+				"GET /api/repos/owner/repo/releases/1",
+			},
+			wantSleeps: func(t *testing.T, sleeps []time.Duration) {
+				if len(sleeps) != 1 || sleeps[0] < initialBackoff/2 || sleeps[0] > 2*initialBackoff {
+					t.Errorf("sleeps = %v, want one backoff around %s", sleeps, initialBackoff)
+				}
+			},
+		},
+		{
+			name:     "5xx on POST is not retried",
+			failures: []failure{{method: "POST", path: "/api/repos/owner/repo/releases", status: http.StatusInternalServerError}},
+			run: func(t *testing.T, c *Client, release *Release) error {
+				_, err := c.CreateRelease("owner", "repo", ReleaseRequest{TagName: "v2.0.0"})
+				return err
+			},
+			wantErr:      true,
+			wantRequests: []string{"POST /api/repos/owner/repo/releases"},
+			wantSleeps: func(t *testing.T, sleeps []time.Duration) {
+				if len(sleeps) != 0 {
+					t.Errorf("sleeps = %v, want none", sleeps)
+				}
+			},
+		},
+		{
+			name: "Retry-After is honored for POST",
+			failures: []failure{{
+				method: "POST", path: "/api/repos/owner/repo/releases", status: http.StatusForbidden,
+				header: map[string]string{"Retry-After": "7"},
+				body:   `{"message":"You have exceeded a secondary rate limit"}`,
+			}},
+			run: func(t *testing.T, c *Client, release *Release) error {
+				_, err := c.CreateRelease("owner", "repo", ReleaseRequest{TagName: "v2.0.0"})
+				return err
+			},
+			wantRequests: []string{
+				"POST /api/repos/owner/repo/releases",
+				"POST /api/repos/owner/repo/releases",
+			},
+			wantSleeps: func(t *testing.T, sleeps []time.Duration) {
+				if !reflect.DeepEqual(sleeps, []time.Duration{7 * time.Second}) {
+					t.Errorf("sleeps = %v, want [7s]", sleeps)
+				}
+			},
+		},
+		{
+			name: "X-RateLimit-Reset is honored",
+			failures: []failure{{
+				method: "GET", path: "/api/repos/owner/repo/releases/1", status: http.StatusForbidden,
+				header: map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": reset},
+				body:   `{"message":"API rate limit exceeded"}`,
+			}},
+			run: func(t *testing.T, c *Client, release *Release) error {
+				_, err := c.GetRelease("owner", "repo", release.ID)
+				return err
+			},
+			wantRequests: []string{
+				"GET /api/repos/owner/repo/releases/1",
+				"GET /api/repos/owner/repo/releases/1",
+			},
+			wantSleeps: func(t *testing.T, sleeps []time.Duration) {
+				if len(sleeps) != 1 || sleeps[0] < 8*time.Second || sleeps[0] > 12*time.Second {
+					t.Errorf("sleeps = %v, want one delay until the reset", sleeps)
+				}
+			},
+		},
+		{
+			name: "partial asset is deleted before re-upload",
+			failures: []failure{{
+				method: "POST", path: "/uploads/repos/owner/repo/releases/1/assets",
+				status: http.StatusBadGateway, partial: true,
+			}},
+			run: func(t *testing.T, c *Client, release *Release) error {
+				_, err := c.UploadAsset("owner", "repo", release, "demo.tar.gz", writeAsset(t, "archive"))
+				return err
+			},
+			wantRequests: []string{
+				"POST /uploads/repos/owner/repo/releases/1/assets",
+				"GET /api/repos/owner/repo/releases/1",
+				"POST /uploads/repos/owner/repo/releases/1/assets",
+			},
+			wantDeletes: 1,
+			wantSleeps: func(t *testing.T, sleeps []time.Duration) {
+				if len(sleeps) != 1 {
+					t.Errorf("sleeps = %v, want one", sleeps)
+				}
+			},
+		},
+		{
+			name: "attempts are limited",
+			failures: []failure{
+				{method: "GET", path: "/api/repos/owner/repo/releases/1", status: http.StatusServiceUnavailable},
+				{method: "GET", path: "/api/repos/owner/repo/releases/1", status: http.StatusServiceUnavailable},
+				{method: "GET", path: "/api/repos/owner/repo/releases/1", status: http.StatusServiceUnavailable},
+			},
+			run: func(t *testing.T, c *Client, release *Release) error {
+				_, err := c.GetRelease("owner", "repo", release.ID)
+				return err
+			},
+			wantErr: true,
+			wantRequests: []string{
+				"GET /api/repos/owner/repo/releases/1",
+				"GET /api/repos/owner/repo/releases/1",
+				"GET /api/repos/owner/repo/releases/1",
+			},
+			wantSleeps: func(t *testing.T, sleeps []time.Duration) {
+				if len(sleeps) != 2 {
+					t.Errorf("sleeps = %v, want two", sleeps)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI(t)
+			release := api.addRelease(Release{TagName: "v1.0.0"})
+			api.failures = tt.failures
+
+			var sleeps []time.Duration
+			client := api.client()
+			client.MaxAttempts = 3
+			client.sleep = func(d time.Duration) { sleeps = append(sleeps, d) }
+
+			err := tt.run(t, client, release)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %t", err, tt.wantErr)
+			}
+			tt.wantSleeps(t, sleeps)
+
+			var requests, deletes []string
+			for _, r := range api.requests {
+				if strings.HasPrefix(r, "DELETE ") {
+					deletes = append(deletes, r)
+				} else {
+					requests = append(requests, r)
+				}
+			}
+			if !reflect.DeepEqual(requests, tt.wantRequests) {
+				t.Errorf("requests = %q, want %q", requests, tt.wantRequests)
+			}
+
+			// Every partially stored asset is deleted before the last upload,
+			// which leaves exactly one asset
+			if len(api.partial) != tt.wantDeletes || len(deletes) != tt.wantDeletes {
+				t.Fatalf("deleted %q for partial assets %+v, want %d deletes", deletes, api.partial, tt.wantDeletes)
+			}
+			lastUpload := -1
+			for i, r := range api.requests {
+				if strings.HasPrefix(r, "POST /uploads/") {
+					lastUpload = i
+				}
+			}
+			for _, asset := range api.partial {
+				want := fmt.Sprintf("DELETE /api/repos/owner/repo/releases/assets/%d", asset.ID)
+				if i := indexOf(api.requests, want); i < 0 || i > lastUpload {
+					t.Errorf("partial asset %d not deleted before the upload was retried: %q", asset.ID, api.requests)
+				}
+			}
+			if tt.wantDeletes > 0 {
+				if got := api.release(release.ID).Assets; len(got) != 1 {
+					t.Errorf("assets = %+v, want one", got)
+				}
+			}
+		})
+	}
+}
+
+func indexOf(list []string, s string) int {
+	for i, item := range list {
+		if item == s {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestCreateRelease(t *testing.T) {
+	tests := []struct {
+		name         string
+		draft        bool
+		existing     string
+		setup        func(api *fakeAPI)
+		wantErr      string
+		wantRequests []string
+		wantDraft    bool
+	}{
+		{
+			name: "new release is published after uploads",
+			wantRequests: []string{
+				"GET /api/repos/owner/repo/releases/tags/v1.0.0",
+				"GET /api/repos/owner/repo/releases",
+				"POST /api/repos/owner/repo/releases",
+				"POST /uploads/repos/owner/repo/releases/1/assets",
+				"PATCH /api/repos/owner/repo/releases/1",
+			},
+		},
+		{
+			name:  "draft release is left as draft",
+			draft: true,
+			wantRequests: []string{
+				"GET /api/repos/owner/repo/releases/tags/v1.0.0",
+				"GET /api/repos/owner/repo/releases",
+				"POST /api/repos/owner/repo/releases",
+				"POST /uploads/repos/owner/repo/releases/1/assets",
+			},
+			wantDraft: true,
+		},
+		{
+			name:    "existing release fails by default",
+			setup:   func(api *fakeAPI) { api.addRelease(Release{TagName: "v1.0.0"}) },
+			wantErr: "already exists",
+			wantRequests: []string{
+				"GET /api/repos/owner/repo/releases/tags/v1.0.0",
+			},
+		},
+		{
+			name:     "existing draft is kept and published",
+			existing: "keep",
+			setup:    func(api *fakeAPI) { api.addRelease(Release{TagName: "v1.0.0", Draft: true}) },
+			wantRequests: []string{
+				"GET /api/repos/owner/repo/releases/tags/v1.0.0",
+				"GET /api/repos/owner/repo/releases",
+				"POST /uploads/repos/owner/repo/releases/1/assets",
+				"PATCH /api/repos/owner/repo/releases/1",
+			},
+		},
+		{
+			name:     "existing release is updated and its stale asset replaced",
+			existing: "update",
+			setup: func(api *fakeAPI) {
+				api.addRelease(Release{TagName: "v1.0.0", Assets: []Asset{{ID: 7, Name: "demo.tar.gz", Size: 1, State: "uploaded"}}})
+			},
+			wantRequests: []string{
+				"GET /api/repos/owner/repo/releases/tags/v1.0.0",
+				"PATCH /api/repos/owner/repo/releases/1",
+				"DELETE /api/repos/owner/repo/releases/assets/7",
+				"POST /uploads/repos/owner/repo/releases/1/assets",
+			},
+		},
+		{
+			name:     "uploaded assets are not uploaded again",
+			existing: "keep",
+			setup: func(api *fakeAPI) {
+				api.addRelease(Release{TagName: "v1.0.0", Assets: []Asset{{ID: 7, Name: "demo.tar.gz", Size: int64(len("archive")), State: "uploaded"}}})
+			},
+			wantRequests: []string{
+				"GET /api/repos/owner/repo/releases/tags/v1.0.0",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI(t)
+			if tt.setup != nil {
+				tt.setup(api)
+			}
+
+			cfg := &config.Config{}
+			cfg.Release.Draft = tt.draft
+			cfg.Release.Prerelease = "auto"
+			cfg.Release.Existing = tt.existing
+
+			err := CreateRelease(ReleaseOptions{
+				Version: "1.0.0",
+				Body:    "notes",
+				Repo:    "owner/repo",
+				Assets:  []assets.Asset{{Name: "demo.tar.gz", Path: writeAsset(t, "archive")}},
+				Config:  cfg,
+				Client:  api.client(),
+			})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(api.requests, tt.wantRequests) {
+				t.Errorf("requests = %q, want %q", api.requests, tt.wantRequests)
+			}
+			if tt.wantErr == "" {
+				if r := api.release(1); r.Draft != tt.wantDraft || len(r.Assets) != 1 {
+					t.Errorf("release = %+v, want draft %t with one asset", r, tt.wantDraft)
+				}
+			}
+		})
+	}
+}
@@ -3,8 +3,6 @@ package github
 import (
 	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
@@ -13,17 +11,24 @@ import (
 	"github.com/fatih/color"
 	"github.com/schollz/progressbar/v3"
 
+	"goreleaser-helper/internal/assets"
 	"goreleaser-helper/internal/build"
+	"goreleaser-helper/internal/checksum"
 	"goreleaser-helper/internal/config"
+	"goreleaser-helper/internal/semver"
 )
 
 // ReleaseOptions contains the options for creating a GitHub release
 type ReleaseOptions struct {
 	Version  string
+	Body     string // Release notes, a generic message is used when empty
 	Repo     string
 	Token    string
 	Binaries []build.BuildResult
+	Assets   []assets.Asset // Extra files uploaded alongside the binaries
 	Config   *config.Config
+	DryRun   bool    // Print the release payload and assets instead of publishing
+	Client   *Client // Client to use, created from Config and Token when nil
 }
 
 // CreateRelease creates a new GitHub release
@@ -34,89 +39,194 @@ func CreateRelease(opts ReleaseOptions) error {
 		return fmt.Errorf("failed to parse repository URL: %w", err)
 	}
 
-	color.Blue("🚀 Creating release v%s for %s/%s...", opts.Version, owner, repoName)
+	client := opts.Client
+	if client == nil {
+		client = NewClient(opts.Config, opts.Token)
+	}
 
-	// Create release
-	releaseID, err := createRelease(owner, repoName, opts)
-	if err != nil {
-		return fmt.Errorf("failed to create release: %w", err)
+	if opts.DryRun {
+		return printRelease(client, owner, repoName, opts)
 	}
 
-	color.Green("✅ Release created successfully!")
+	request := newReleaseRequest(opts)
+	release, err := prepareRelease(client, owner, repoName, request, opts.Config.Release.Existing)
+	if err != nil {
+		return err
+	}
 
 	// Upload assets
 	color.Blue("📦 Uploading assets...")
-	if err := uploadAssets(owner, repoName, releaseID, opts); err != nil {
+	if err := uploadAssets(client, owner, repoName, release, opts); err != nil {
 		return fmt.Errorf("failed to upload assets: %w", err)
 	}
 
 	color.Green("✅ All assets uploaded successfully!")
+
+	// Publish the release unless a draft was requested
+	if request.Draft {
+		color.Yellow("📝 Release left as draft: %s", release.HTMLURL)
+		return nil
+	}
+	if release.Draft {
+		if _, err := client.PublishRelease(owner, repoName, release.ID); err != nil {
+			return fmt.Errorf("failed to publish release: %w", err)
+		}
+		color.Green("✅ Release published!")
+	}
+
 	return nil
 }
 
+// prepareRelease returns the release to upload assets to. Depending on the
+// existing mode, a release that already exists for the tag is reused,
+// updated or reported as an error. New releases are created as drafts so
+// that they only become visible once every asset has been uploaded.
+func prepareRelease(client *Client, owner, repo string, request ReleaseRequest, existing string) (*Release, error) {
+	release, err := client.FindReleaseByTag(owner, repo, request.TagName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up release %s: %w", request.TagName, err)
+	}
+
+	if release == nil {
+		color.Blue("🚀 Creating release %s for %s/%s...", request.TagName, owner, repo)
+		draft := request
+		draft.Draft = true
+		release, err = client.CreateRelease(owner, repo, draft)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create release: %w", err)
+		}
+		color.Green("✅ Release created successfully!")
+		return release, nil
+	}
+
+	switch existing {
+	case "keep":
+		color.Yellow("♻️  Reusing existing release %s", request.TagName)
+	case "update":
+		color.Yellow("♻️  Updating existing release %s", request.TagName)
+		// Keep the current draft state, publishing happens after uploads
+		update := request
+		update.Draft = release.Draft
+		if release, err = client.UpdateRelease(owner, repo, release.ID, update); err != nil {
+			return nil, fmt.Errorf("failed to update release: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("release %s already exists (set release.existing to keep or update to reuse it)", request.TagName)
+	}
+
+	return release, nil
+}
+
+// parseRepoURL splits a repository into owner and name, accepting the forms
+// of config.RepoName
 func parseRepoURL(repo string) (string, string, error) {
-	parts := strings.Split(repo, "/")
+	parts := strings.Split(config.RepoName(repo), "/")
 	if len(parts) != 2 {
 		return "", "", fmt.Errorf("invalid repository format: %s", repo)
 	}
 	return parts[0], parts[1], nil
 }
 
-func createRelease(owner, repo string, opts ReleaseOptions) (string, error) {
-	// Prepare release data
-	data := fmt.Sprintf(`{
-		"tag_name": "v%s",
-		"name": "Release v%s",
-		"body": "Release v%s",
-		"draft": false,
-		"prerelease": false
-	}`, opts.Version, opts.Version, opts.Version)
-
-	// Create HTTP request
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/releases", owner, repo)
-	req, err := http.NewRequest("POST", url, strings.NewReader(data))
+// printRelease shows what CreateRelease would send without making any
+// network calls
+func printRelease(client *Client, owner, repo string, opts ReleaseOptions) error {
+	uploads, err := releaseAssets(opts)
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return err
 	}
 
-	// Set headers
-	req.Header.Set("Authorization", "token "+opts.Token)
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/vnd.github.v3+json")
-
-	// Send request
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	data, err := json.MarshalIndent(newReleaseRequest(opts), "", "  ")
 	if err != nil {
-		return "", fmt.Errorf("failed to send request: %w", err)
+		return fmt.Errorf("failed to marshal release payload: %w", err)
 	}
-	defer resp.Body.Close()
 
-	// Read response
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
+	color.Yellow("🧪 Dry run: not publishing release %s for %s/%s", semver.TagName(opts.Version), owner, repo)
+	fmt.Printf("POST %s\n%s\n\n", client.releasesURL(owner, repo), data)
+	if !opts.Config.Release.Draft {
+		fmt.Printf("The release is created as a draft and published once all assets are uploaded.\n\n")
+	}
+
+	fmt.Printf("Assets (%d):\n", len(uploads))
+	for _, asset := range uploads {
+		info, err := os.Stat(asset.Path)
+		if err != nil {
+			return fmt.Errorf("failed to stat asset %s: %w", asset.Name, err)
+		}
+		fmt.Printf("  %-50s %10d bytes  (%s)\n", asset.Name, info.Size(), asset.Path)
+	}
+
+	return nil
+}
+
+func newReleaseRequest(opts ReleaseOptions) ReleaseRequest {
+	tag := semver.TagName(opts.Version)
+	body := opts.Body
+	if body == "" {
+		body = "Release " + tag
+	}
+
+	// Prerelease is "auto", "true" or "false"
+	prerelease := opts.Config.Release.Prerelease == "true"
+	if opts.Config.Release.Prerelease == "auto" {
+		v, err := semver.Parse(opts.Version)
+		prerelease = err == nil && v.IsPrerelease()
 	}
 
-	// Check response status
-	if resp.StatusCode != http.StatusCreated {
-		return "", fmt.Errorf("failed to create release: %s", string(body))
+	return ReleaseRequest{
+		TagName:    tag,
+		Name:       "Release " + tag,
+		Body:       body,
+		Draft:      opts.Config.Release.Draft,
+		Prerelease: prerelease,
 	}
+}
+
+// releaseAssets returns the binaries and extra assets to upload, ensuring
+// that no two files share an asset name
+func releaseAssets(opts ReleaseOptions) ([]assets.Asset, error) {
+	var result []assets.Asset
+	seen := make(map[string]string)
 
-	// Parse the JSON response to get the release ID
-	var respData struct {
-		ID int64 `json:"id"`
+	add := func(a assets.Asset) error {
+		if existing, ok := seen[a.Name]; ok {
+			if existing == a.Path {
+				return nil
+			}
+			return fmt.Errorf("asset name %s is used by both %s and %s", a.Name, existing, a.Path)
+		}
+		seen[a.Name] = a.Path
+		result = append(result, a)
+		return nil
 	}
-	if err := json.Unmarshal(body, &respData); err != nil {
-		return "", fmt.Errorf("failed to parse release response: %w", err)
+
+	for _, binary := range opts.Binaries {
+		if err := add(assets.Asset{Name: filepath.Base(binary.Path), Path: binary.Path}); err != nil {
+			return nil, err
+		}
+	}
+	for _, asset := range opts.Assets {
+		if err := add(asset); err != nil {
+			return nil, err
+		}
 	}
 
-	return fmt.Sprintf("%d", respData.ID), nil
+	return result, nil
 }
 
-func uploadAssets(owner, repo, releaseID string, opts ReleaseOptions) error {
+func uploadAssets(client *Client, owner, repo string, release *Release, opts ReleaseOptions) error {
+	uploads, err := releaseAssets(opts)
+	if err != nil {
+		return err
+	}
+
+	// Index assets that already exist on a reused release
+	existing := make(map[string]Asset)
+	for _, asset := range release.Assets {
+		existing[asset.Name] = asset
+	}
+
 	// Create progress bar
-	bar := progressbar.NewOptions(len(opts.Binaries),
+	bar := progressbar.NewOptions(len(uploads),
 		progressbar.OptionSetDescription("Uploading assets..."),
 		progressbar.OptionShowCount(),
 		progressbar.OptionSetTheme(progressbar.Theme{
@@ -129,20 +239,38 @@ func uploadAssets(owner, repo, releaseID string, opts ReleaseOptions) error {
 	)
 
 	// Create error channel and wait group
-	errChan := make(chan error, len(opts.Binaries))
+	errChan := make(chan error, len(uploads))
 	var wg sync.WaitGroup
 
 	// Upload assets concurrently
-	for _, binary := range opts.Binaries {
+	for _, asset := range uploads {
 		wg.Add(1)
-		go func(b build.BuildResult) {
+		go func(a assets.Asset) {
 			defer wg.Done()
-			if err := uploadSingleAsset(owner, repo, releaseID, opts.Token, b); err != nil {
-				errChan <- fmt.Errorf("failed to upload %s: %w", filepath.Base(b.Path), err)
+
+			// Skip assets that are already uploaded, replace stale ones
+			if current, ok := existing[a.Name]; ok {
+				same, err := sameAsset(current, a)
+				if err != nil {
+					errChan <- fmt.Errorf("failed to compare %s: %w", a.Name, err)
+					return
+				}
+				if same {
+					bar.Add(1)
+					return
+				}
+				if err := client.DeleteAsset(owner, repo, current.ID); err != nil {
+					errChan <- fmt.Errorf("failed to replace %s: %w", a.Name, err)
+					return
+				}
+			}
+
+			if _, err := client.UploadAsset(owner, repo, release, a.Name, a.Path); err != nil {
+				errChan <- fmt.Errorf("failed to upload %s: %w", a.Name, err)
 				return
 			}
 			bar.Add(1)
-		}(binary)
+		}(asset)
 	}
 
 	// Wait for all uploads to complete
@@ -159,50 +287,28 @@ func uploadAssets(owner, repo, releaseID string, opts ReleaseOptions) error {
 	return nil
 }
 
-func uploadSingleAsset(owner, repo, releaseID, token string, binary build.BuildResult) error {
-	file, err := os.Open(binary.Path)
-	if err != nil {
-		return fmt.Errorf("failed to open file %s: %w", binary.Path, err)
-	}
-	defer file.Close()
-
-	fileInfo, err := file.Stat()
-	if err != nil {
-		return fmt.Errorf("failed to get file info: %w", err)
-	}
-
-	// Ensure file pointer is at the start
-	if _, err := file.Seek(0, io.SeekStart); err != nil {
-		return fmt.Errorf("failed to seek file: %w", err)
+// sameAsset reports whether the uploaded asset matches the local file. The
+// size is always compared; the checksum only when the server reports one.
+func sameAsset(uploaded Asset, local assets.Asset) (bool, error) {
+	if uploaded.State != "" && uploaded.State != "uploaded" {
+		return false, nil
 	}
 
-	assetName := filepath.Base(binary.Path)
-	uploadURL := fmt.Sprintf(
-		"https://uploads.github.com/repos/%s/%s/releases/%s/assets?name=%s",
-		owner, repo, releaseID, assetName,
-	)
-
-	req, err := http.NewRequest("POST", uploadURL, file)
+	info, err := os.Stat(local.Path)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return false, err
 	}
-
-	req.Header.Set("Authorization", "token "+token)
-	req.Header.Set("Content-Type", "application/octet-stream")
-	req.Header.Set("Accept", "application/vnd.github.v3+json")
-	req.ContentLength = fileInfo.Size()
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
+	if info.Size() != uploaded.Size {
+		return false, nil
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusCreated {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("failed to upload asset: %s", string(body))
+	if algorithm, digest, ok := strings.Cut(uploaded.Digest, ":"); ok && algorithm == "sha256" {
+		sum, err := checksum.File(local.Path, "sha256")
+		if err != nil {
+			return false, err
+		}
+		return strings.EqualFold(sum, digest), nil
 	}
 
-	return nil
+	return true, nil
 }
@@ -0,0 +1,29 @@
+package github
+
+import "testing"
+
+func TestParseRepoURL(t *testing.T) {
+	tests := []struct {
+		repo      string
+		wantOwner string
+		wantName  string
+		wantErr   bool
+	}{
+		{repo: "owner/repo", wantOwner: "owner", wantName: "repo"},
+		{repo: "github.com/owner/repo", wantOwner: "owner", wantName: "repo"},
+		{repo: "ghe.example.com/owner/repo", wantOwner: "owner", wantName: "repo"},
+		{repo: "https://github.com/owner/repo.git", wantOwner: "owner", wantName: "repo"},
+		{repo: "repo", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		owner, name, err := parseRepoURL(tt.repo)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseRepoURL(%q) error = %v, wantErr %t", tt.repo, err, tt.wantErr)
+			continue
+		}
+		if owner != tt.wantOwner || name != tt.wantName {
+			t.Errorf("parseRepoURL(%q) = %q, %q, want %q, %q", tt.repo, owner, name, tt.wantOwner, tt.wantName)
+		}
+	}
+}
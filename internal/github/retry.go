@@ -0,0 +1,112 @@
+package github
+
+import (
+	"errors"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+const (
+	initialBackoff = time.Second
+	maxBackoff     = 30 * time.Second
+)
+
+// retry calls fn until it succeeds, fails with a permanent error or the
+// maximum number of attempts is reached. Server and network errors are only
+// retried for idempotent operations; rate limit responses always are.
+func (c *Client) retry(idempotent bool, fn func(attempt int) error) error {
+	maxAttempts := c.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	sleep := c.sleep
+	if sleep == nil {
+		sleep = time.Sleep
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := fn(attempt)
+		if err == nil || attempt >= maxAttempts {
+			return err
+		}
+
+		delay, ok := retryDelay(err, attempt, idempotent)
+		if !ok {
+			return err
+		}
+
+		color.Yellow("⏳ %v, retrying in %s (attempt %d/%d)", err, delay.Round(time.Second), attempt+1, maxAttempts)
+		sleep(delay)
+	}
+}
+
+// retryDelay reports whether err is worth retrying and how long to wait
+// before the next attempt
+func retryDelay(err error, attempt int, idempotent bool) (time.Duration, bool) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		if delay, ok := rateLimitDelay(apiErr); ok {
+			return delay, true
+		}
+		switch apiErr.StatusCode {
+		case http.StatusInternalServerError, http.StatusBadGateway,
+			http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return backoff(attempt), idempotent
+		}
+		return 0, false
+	}
+
+	// Transport errors such as timeouts and connection resets
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return backoff(attempt), idempotent
+	}
+
+	return 0, false
+}
+
+// rateLimitDelay returns the wait time for primary and secondary rate limit
+// responses, based on the Retry-After and X-RateLimit-Reset headers
+func rateLimitDelay(err *APIError) (time.Duration, bool) {
+	if err.StatusCode != http.StatusForbidden && err.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+
+	if retryAfter := err.Header.Get("Retry-After"); retryAfter != "" {
+		if seconds, parseErr := strconv.Atoi(retryAfter); parseErr == nil {
+			return time.Duration(seconds) * time.Second, true
+		}
+	}
+
+	if err.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, parseErr := strconv.ParseInt(err.Header.Get("X-RateLimit-Reset"), 10, 64); parseErr == nil {
+			delay := time.Until(time.Unix(reset, 0))
+			if delay < 0 {
+				delay = 0
+			}
+			return delay + time.Second, true
+		}
+	}
+
+	// Secondary rate limits without headers: wait at least a minute
+	if err.StatusCode == http.StatusTooManyRequests || strings.Contains(strings.ToLower(err.Message), "rate limit") {
+		return time.Minute, true
+	}
+
+	return 0, false
+}
+
+// backoff returns an exponential delay with random jitter for the given attempt
+func backoff(attempt int) time.Duration {
+	delay := initialBackoff << (attempt - 1)
+	if delay > maxBackoff || delay <= 0 {
+		delay = maxBackoff
+	}
+	return time.Duration(rand.Int63n(int64(delay))) + delay/2
+}
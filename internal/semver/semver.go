@@ -0,0 +1,229 @@
+package semver
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Version is a semantic version as defined by SemVer 2.0.0
+type Version struct {
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease string // Dot-separated prerelease identifiers, e.g. "rc.1"
+	Build      string // Dot-separated build metadata, ignored for precedence
+}
+
+// versionRegexp is the regular expression suggested by semver.org, with an
+// optional "v" prefix
+var versionRegexp = regexp.MustCompile(`^v?(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)` +
+	`(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?` +
+	`(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`)
+
+// Parse parses a version with an optional "v" prefix
+func Parse(s string) (Version, error) {
+	matches := versionRegexp.FindStringSubmatch(s)
+	if matches == nil {
+		return Version{}, fmt.Errorf("invalid semantic version: %s", s)
+	}
+
+	var v Version
+	var err error
+	if v.Major, err = strconv.Atoi(matches[1]); err != nil {
+		return Version{}, fmt.Errorf("invalid major version: %w", err)
+	}
+	if v.Minor, err = strconv.Atoi(matches[2]); err != nil {
+		return Version{}, fmt.Errorf("invalid minor version: %w", err)
+	}
+	if v.Patch, err = strconv.Atoi(matches[3]); err != nil {
+		return Version{}, fmt.Errorf("invalid patch version: %w", err)
+	}
+	v.Prerelease = matches[4]
+	v.Build = matches[5]
+
+	return v, nil
+}
+
+// IsValid reports whether s is a semantic version with an optional "v"
+// prefix
+func IsValid(s string) bool {
+	return versionRegexp.MatchString(s)
+}
+
+// String returns the canonical version without a "v" prefix
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	if v.Build != "" {
+		s += "+" + v.Build
+	}
+	return s
+}
+
+// Tag returns the git tag for the version, which always has a single "v"
+// prefix
+func (v Version) Tag() string {
+	return "v" + v.String()
+}
+
+// TagName returns the git tag for a version string, adding a "v" prefix
+// unless it already has one
+func TagName(version string) string {
+	return "v" + strings.TrimPrefix(version, "v")
+}
+
+// IsPrerelease reports whether the version has prerelease identifiers
+func (v Version) IsPrerelease() bool {
+	return v.Prerelease != ""
+}
+
+// Compare returns -1, 0 or 1 depending on whether v has lower, equal or
+// higher precedence than o. Build metadata is ignored.
+func (v Version) Compare(o Version) int {
+	if c := compareInt(v.Major, o.Major); c != 0 {
+		return c
+	}
+	if c := compareInt(v.Minor, o.Minor); c != 0 {
+		return c
+	}
+	if c := compareInt(v.Patch, o.Patch); c != 0 {
+		return c
+	}
+	return comparePrerelease(v.Prerelease, o.Prerelease)
+}
+
+// LessThan reports whether v has lower precedence than o
+func (v Version) LessThan(o Version) bool {
+	return v.Compare(o) < 0
+}
+
+// comparePrerelease compares prerelease identifiers: a version without
+// prerelease has higher precedence, numeric identifiers compare
+// numerically and have lower precedence than alphanumeric ones, and a
+// larger set of identifiers wins if all preceding ones are equal
+func comparePrerelease(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+
+	aIDs := strings.Split(a, ".")
+	bIDs := strings.Split(b, ".")
+	for i := 0; i < len(aIDs) && i < len(bIDs); i++ {
+		aNum, bNum := isNumeric(aIDs[i]), isNumeric(bIDs[i])
+		switch {
+		case aNum && bNum:
+			if c := compareNumeric(aIDs[i], bIDs[i]); c != 0 {
+				return c
+			}
+		case aNum:
+			return -1
+		case bNum:
+			return 1
+		default:
+			if c := strings.Compare(aIDs[i], bIDs[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return compareInt(len(aIDs), len(bIDs))
+}
+
+// isNumeric reports whether a prerelease identifier consists only of ASCII
+// digits
+func isNumeric(id string) bool {
+	if id == "" {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < '0' || id[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// compareNumeric compares numeric identifiers of any size. They have no
+// leading zeros, so the longer one is larger and equal lengths compare
+// lexically.
+func compareNumeric(a, b string) int {
+	if c := compareInt(len(a), len(b)); c != 0 {
+		return c
+	}
+	return strings.Compare(a, b)
+}
+
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// BumpMajor returns the next major version. A prerelease of a major
+// version, such as 2.0.0-rc.1, becomes that major version.
+func (v Version) BumpMajor() Version {
+	if v.Prerelease != "" && v.Minor == 0 && v.Patch == 0 {
+		return Version{Major: v.Major}
+	}
+	return Version{Major: v.Major + 1}
+}
+
+// BumpMinor returns the next minor version. A prerelease of a minor
+// version, such as 1.2.0-rc.1, becomes that minor version.
+func (v Version) BumpMinor() Version {
+	if v.Prerelease != "" && v.Patch == 0 {
+		return Version{Major: v.Major, Minor: v.Minor}
+	}
+	return Version{Major: v.Major, Minor: v.Minor + 1}
+}
+
+// BumpPatch returns the next patch version. A prerelease becomes the
+// version it is a prerelease of.
+func (v Version) BumpPatch() Version {
+	if v.Prerelease != "" {
+		return Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch}
+	}
+	return Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch + 1}
+}
+
+// NextPrerelease returns the next prerelease of v with the given
+// identifier: 1.2.0-rc.1 becomes 1.2.0-rc.2, 1.2.0-rc.1.2 becomes
+// 1.2.0-rc.1.3 and 1.2.0-rc becomes 1.2.0-rc.1. A version with a different
+// identifier starts over at <id>.1, such as 1.2.0-alpha.3 becoming
+// 1.2.0-beta.1. An error is returned if the result would not have higher
+// precedence than v, which is the case for a stable v or when switching
+// back from rc to beta.
+func (v Version) NextPrerelease(id string) (Version, error) {
+	next := Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch}
+	ids := strings.Split(v.Prerelease, ".")
+	switch last := ids[len(ids)-1]; {
+	case ids[0] != id:
+		next.Prerelease = id + ".1"
+	case len(ids) > 1 && isNumeric(last):
+		n, err := strconv.Atoi(last)
+		if err != nil {
+			return Version{}, fmt.Errorf("invalid prerelease number %s: %w", last, err)
+		}
+		ids[len(ids)-1] = strconv.Itoa(n + 1)
+		next.Prerelease = strings.Join(ids, ".")
+	default:
+		next.Prerelease = v.Prerelease + ".1"
+	}
+
+	if !v.LessThan(next) {
+		return Version{}, fmt.Errorf("next %s prerelease %s does not have higher precedence than %s", id, next, v)
+	}
+	return next, nil
+}
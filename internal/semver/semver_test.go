@@ -0,0 +1,198 @@
+package semver
+
+import "testing"
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.0.0", "1.0.0", 0},
+		{"1.0.0", "2.0.0", -1},
+		{"1.2.0", "1.10.0", -1},
+		{"1.0.0-alpha", "1.0.0", -1},
+		{"1.0.0+build", "1.0.0", 0},
+		// Examples from the SemVer 2.0.0 precedence rules
+		{"1.0.0-alpha", "1.0.0-alpha.1", -1},
+		{"1.0.0-alpha.1", "1.0.0-alpha.beta", -1},
+		{"1.0.0-alpha.beta", "1.0.0-beta", -1},
+		{"1.0.0-beta", "1.0.0-beta.2", -1},
+		{"1.0.0-beta.2", "1.0.0-beta.11", -1},
+		{"1.0.0-beta.11", "1.0.0-rc.1", -1},
+		// A hyphen makes an identifier alphanumeric
+		{"1.0.0-rc.1", "1.0.0-rc.-1", -1},
+		{"1.0.0-rc.-1", "1.0.0-rc.-2", -1},
+		// Numeric identifiers are not limited to int
+		{"1.0.0-99999999999999999999", "1.0.0-100000000000000000000", -1},
+		{"1.0.0-100000000000000000000", "1.0.0-100000000000000000000", 0},
+		{"1.0.0-100000000000000000000", "1.0.0-alpha", -1},
+	}
+
+	for _, tt := range tests {
+		a, err := Parse(tt.a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := Parse(tt.b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := a.Compare(b); got != tt.want {
+			t.Errorf("Compare(%s, %s) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := b.Compare(a); got != -tt.want {
+			t.Errorf("Compare(%s, %s) = %d, want %d", tt.b, tt.a, got, -tt.want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Version
+		wantErr bool
+	}{
+		{in: "1.2.3", want: Version{Major: 1, Minor: 2, Patch: 3}},
+		{in: "v1.2.3", want: Version{Major: 1, Minor: 2, Patch: 3}},
+		{in: "0.0.0", want: Version{}},
+		{in: "1.0.0-rc.1", want: Version{Major: 1, Prerelease: "rc.1"}},
+		{in: "1.0.0-0.3.7", want: Version{Major: 1, Prerelease: "0.3.7"}},
+		{in: "1.0.0-x-y.01a", want: Version{Major: 1, Prerelease: "x-y.01a"}},
+		{in: "1.0.0+build.5", want: Version{Major: 1, Build: "build.5"}},
+		{in: "1.0.0-beta+exp.sha.5114f85", want: Version{Major: 1, Prerelease: "beta", Build: "exp.sha.5114f85"}},
+		{in: "1.0.0+001", want: Version{Major: 1, Build: "001"}},
+		// Leading zeros
+		{in: "01.2.3", wantErr: true},
+		{in: "1.02.3", wantErr: true},
+		{in: "1.2.03", wantErr: true},
+		{in: "1.2.3-rc.01", wantErr: true},
+		// Empty identifiers
+		{in: "1.2.3-", wantErr: true},
+		{in: "1.2.3-rc..1", wantErr: true},
+		{in: "1.2.3-rc.", wantErr: true},
+		{in: "1.2.3+", wantErr: true},
+		{in: "1.2.3+build..5", wantErr: true},
+		// Other invalid versions
+		{in: "", wantErr: true},
+		{in: "1.2", wantErr: true},
+		{in: "1.2.3.4", wantErr: true},
+		{in: "vv1.2.3", wantErr: true},
+		{in: "V1.2.3", wantErr: true},
+		{in: " 1.2.3", wantErr: true},
+		{in: "1.2.3-rc_1", wantErr: true},
+		{in: "Unreleased", wantErr: true},
+		{in: "99999999999999999999.0.0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Parse(%q) error = %v, wantErr %t", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+		if !tt.wantErr && !IsValid(tt.in) {
+			t.Errorf("IsValid(%q) = false", tt.in)
+		}
+	}
+}
+
+func TestBump(t *testing.T) {
+	tests := []struct {
+		in                  string
+		major, minor, patch string
+	}{
+		{"1.2.3", "2.0.0", "1.3.0", "1.2.4"},
+		{"0.1.0", "1.0.0", "0.2.0", "0.1.1"},
+		{"1.2.3+build", "2.0.0", "1.3.0", "1.2.4"},
+		// A prerelease is bumped to the version it is a prerelease of
+		{"2.0.0-rc.1", "2.0.0", "2.0.0", "2.0.0"},
+		{"1.3.0-rc.1", "2.0.0", "1.3.0", "1.3.0"},
+		{"1.2.4-rc.1", "2.0.0", "1.3.0", "1.2.4"},
+	}
+
+	for _, tt := range tests {
+		v, err := Parse(tt.in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := v.BumpMajor().String(); got != tt.major {
+			t.Errorf("%s BumpMajor() = %s, want %s", tt.in, got, tt.major)
+		}
+		if got := v.BumpMinor().String(); got != tt.minor {
+			t.Errorf("%s BumpMinor() = %s, want %s", tt.in, got, tt.minor)
+		}
+		if got := v.BumpPatch().String(); got != tt.patch {
+			t.Errorf("%s BumpPatch() = %s, want %s", tt.in, got, tt.patch)
+		}
+	}
+}
+
+func TestTag(t *testing.T) {
+	tests := []struct {
+		in, canonical, tag string
+	}{
+		{"1.2.3", "1.2.3", "v1.2.3"},
+		{"v1.2.3", "1.2.3", "v1.2.3"},
+		{"v1.0.0-rc.1+build.5", "1.0.0-rc.1+build.5", "v1.0.0-rc.1+build.5"},
+	}
+
+	for _, tt := range tests {
+		v, err := Parse(tt.in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := v.String(); got != tt.canonical {
+			t.Errorf("%s String() = %s, want %s", tt.in, got, tt.canonical)
+		}
+		if got := v.Tag(); got != tt.tag {
+			t.Errorf("%s Tag() = %s, want %s", tt.in, got, tt.tag)
+		}
+		if got := TagName(tt.in); got != tt.tag {
+			t.Errorf("TagName(%s) = %s, want %s", tt.in, got, tt.tag)
+		}
+	}
+}
+
+func TestNextPrerelease(t *testing.T) {
+	tests := []struct {
+		in, id  string
+		want    string
+		wantErr bool
+	}{
+		{in: "1.2.0-rc.1", id: "rc", want: "1.2.0-rc.2"},
+		{in: "1.2.0-rc.9", id: "rc", want: "1.2.0-rc.10"},
+		{in: "1.2.0-rc.1.2", id: "rc", want: "1.2.0-rc.1.3"},
+		{in: "1.2.0-rc", id: "rc", want: "1.2.0-rc.1"},
+		{in: "1.2.0-rc.x", id: "rc", want: "1.2.0-rc.x.1"},
+		{in: "1.2.0-rc.1+build.5", id: "rc", want: "1.2.0-rc.2"},
+		{in: "1.2.0-alpha.3", id: "beta", want: "1.2.0-beta.1"},
+		// The result must not have lower precedence
+		{in: "1.2.0-rc.3", id: "beta", wantErr: true},
+		{in: "1.2.0-rc.1.2", id: "beta", wantErr: true},
+		{in: "1.2.0", id: "rc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		v, err := Parse(tt.in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := v.NextPrerelease(tt.id)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s NextPrerelease(%s) error = %v, wantErr %t", tt.in, tt.id, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if got.String() != tt.want {
+			t.Errorf("%s NextPrerelease(%s) = %s, want %s", tt.in, tt.id, got, tt.want)
+		}
+		if !v.LessThan(got) {
+			t.Errorf("%s NextPrerelease(%s) = %s, not higher", tt.in, tt.id, got)
+		}
+	}
+}
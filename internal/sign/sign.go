@@ -0,0 +1,233 @@
+package sign
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/fatih/color"
+	"golang.org/x/crypto/blake2b"
+
+	"goreleaser-helper/internal/assets"
+)
+
+// Supported key types
+const (
+	TypeGPG     = "gpg"
+	TypeEd25519 = "ed25519"
+)
+
+// Signer produces detached signatures
+type Signer interface {
+	// Sign returns the detached signature of data, the contents of the file
+	// called name
+	Sign(name string, data []byte) ([]byte, error)
+	// Extension is appended to the artifact name to form the signature name
+	Extension() string
+}
+
+// Options contains the options for signing artifacts
+type Options struct {
+	KeyType    string // gpg or ed25519, inferred from the key file when empty
+	KeyPath    string // Path to the private key file
+	Passphrase string // Passphrase for encrypted OpenPGP keys
+	Artifacts  []assets.Asset
+}
+
+// SignArtifacts writes a detached signature next to every artifact and
+// returns the signature files as release assets
+func SignArtifacts(opts Options) ([]assets.Asset, error) {
+	signer, err := NewSigner(opts.KeyType, opts.KeyPath, opts.Passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	color.Blue("✍️  Signing %d artifacts...", len(opts.Artifacts))
+	if s, ok := signer.(*ed25519Signer); ok {
+		color.Blue("🔑 minisign public key: %s", s.PublicKey())
+	}
+
+	var signatures []assets.Asset
+	for _, artifact := range opts.Artifacts {
+		data, err := os.ReadFile(artifact.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", artifact.Name, err)
+		}
+
+		sig, err := signer.Sign(artifact.Name, data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign %s: %w", artifact.Name, err)
+		}
+
+		path := artifact.Path + signer.Extension()
+		if err := os.WriteFile(path, sig, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write signature for %s: %w", artifact.Name, err)
+		}
+		signatures = append(signatures, assets.Asset{Name: artifact.Name + signer.Extension(), Path: path})
+	}
+
+	color.Green("✅ Artifacts signed successfully!")
+	return signatures, nil
+}
+
+// NewSigner loads the private key at keyPath. An empty keyType selects
+// ed25519 for PEM-encoded PKCS#8 keys and OpenPGP otherwise.
+func NewSigner(keyType, keyPath, passphrase string) (Signer, error) {
+	if keyPath == "" {
+		return nil, fmt.Errorf("no signing key configured")
+	}
+	data, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+
+	if keyType == "" {
+		keyType = TypeGPG
+		if block, _ := pem.Decode(data); block != nil && block.Type == "PRIVATE KEY" {
+			keyType = TypeEd25519
+		}
+	}
+
+	switch keyType {
+	case TypeGPG:
+		return newGPGSigner(data, passphrase)
+	case TypeEd25519:
+		return newEd25519Signer(data, passphrase)
+	default:
+		return nil, fmt.Errorf("unsupported signing key type: %s", keyType)
+	}
+}
+
+// gpgSigner creates ASCII-armored OpenPGP detached signatures
+type gpgSigner struct {
+	entity *openpgp.Entity
+}
+
+func newGPGSigner(data []byte, passphrase string) (*gpgSigner, error) {
+	// Accept both armored and binary keyrings
+	keyring, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(data))
+	if err != nil {
+		keyring, err = openpgp.ReadKeyRing(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse OpenPGP key: %w", err)
+		}
+	}
+
+	// Use the first entity that holds a private key
+	var entity *openpgp.Entity
+	for _, e := range keyring {
+		if e.PrivateKey != nil {
+			entity = e
+			break
+		}
+	}
+	if entity == nil {
+		return nil, fmt.Errorf("OpenPGP key file does not contain a private key")
+	}
+
+	// Decrypt the primary key and any signing subkeys
+	if entity.PrivateKey.Encrypted {
+		if passphrase == "" {
+			return nil, fmt.Errorf("OpenPGP key is encrypted but no passphrase was provided")
+		}
+		if err := entity.PrivateKey.Decrypt([]byte(passphrase)); err != nil {
+			return nil, fmt.Errorf("failed to decrypt OpenPGP key: %w", err)
+		}
+	}
+	for _, subkey := range entity.Subkeys {
+		if subkey.PrivateKey != nil && subkey.PrivateKey.Encrypted {
+			if err := subkey.PrivateKey.Decrypt([]byte(passphrase)); err != nil {
+				return nil, fmt.Errorf("failed to decrypt OpenPGP subkey: %w", err)
+			}
+		}
+	}
+
+	return &gpgSigner{entity: entity}, nil
+}
+
+func (s *gpgSigner) Sign(name string, data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := openpgp.ArmoredDetachSign(&buf, s.entity, bytes.NewReader(data), nil); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *gpgSigner) Extension() string {
+	return ".asc"
+}
+
+// ed25519Signer creates minisign signatures, which can be verified with
+// "minisign -V -P <public key> -m <file>"
+type ed25519Signer struct {
+	key   ed25519.PrivateKey
+	keyID [8]byte
+}
+
+func newEd25519Signer(data []byte, passphrase string) (*ed25519Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("ed25519 key must be PEM encoded")
+	}
+	// PKCS#8 keys are read unencrypted, so a passphrase is most likely meant
+	// for another key
+	if passphrase != "" {
+		return nil, fmt.Errorf("a passphrase is set but the ed25519 key is not encrypted")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ed25519 key: %w", err)
+	}
+	edKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("key is not an ed25519 private key")
+	}
+
+	// minisign identifies keys by a random ID stored in the key files. PEM
+	// keys have none, so derive it from the public key.
+	s := &ed25519Signer{key: edKey}
+	sum := blake2b.Sum256(edKey.Public().(ed25519.PublicKey))
+	copy(s.keyID[:], sum[:8])
+	return s, nil
+}
+
+// PublicKey returns the public key in minisign format
+func (s *ed25519Signer) PublicKey() string {
+	var key []byte
+	key = append(key, "Ed"...)
+	key = append(key, s.keyID[:]...)
+	key = append(key, s.key.Public().(ed25519.PublicKey)...)
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+// Sign writes a prehashed minisign signature: the signature of the BLAKE2b
+// hash of data, and a global signature that also covers the trusted
+// comment with the signing time and file name
+func (s *ed25519Signer) Sign(name string, data []byte) ([]byte, error) {
+	hash := blake2b.Sum512(data)
+	var sig []byte
+	sig = append(sig, "ED"...)
+	sig = append(sig, s.keyID[:]...)
+	sig = append(sig, ed25519.Sign(s.key, hash[:])...)
+
+	trusted := fmt.Sprintf("timestamp:%d\tfile:%s\thashed", time.Now().Unix(), name)
+	global := ed25519.Sign(s.key, append(append([]byte{}, sig[10:]...), trusted...))
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "untrusted comment: signature from goreleaser-helper key %016X\n", binary.LittleEndian.Uint64(s.keyID[:]))
+	fmt.Fprintf(&buf, "%s\n", base64.StdEncoding.EncodeToString(sig))
+	fmt.Fprintf(&buf, "trusted comment: %s\n", trusted)
+	fmt.Fprintf(&buf, "%s\n", base64.StdEncoding.EncodeToString(global))
+	return buf.Bytes(), nil
+}
+
+func (s *ed25519Signer) Extension() string {
+	return ".minisig"
+}
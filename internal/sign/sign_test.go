@@ -0,0 +1,213 @@
+package sign
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"golang.org/x/crypto/blake2b"
+
+	"goreleaser-helper/internal/assets"
+)
+
+// writeFile writes data to name in dir and returns its path
+func writeFile(t *testing.T, dir, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// gpgKey generates an armored OpenPGP private key, encrypted if passphrase
+// is set, and returns the entity for verification
+func gpgKey(t *testing.T, passphrase string) (*openpgp.Entity, []byte) {
+	t.Helper()
+	entity, err := openpgp.NewEntity("Release Bot", "", "release@example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	w, err := armor.Encode(&buf, openpgp.PrivateKeyType, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if passphrase != "" {
+		if err := entity.EncryptPrivateKeys([]byte(passphrase), nil); err != nil {
+			t.Fatal(err)
+		}
+		err = entity.SerializePrivateWithoutSigning(w, nil)
+	} else {
+		err = entity.SerializePrivate(w, nil)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	return entity, buf.Bytes()
+}
+
+// ed25519Key generates a PEM-encoded PKCS#8 ed25519 private key
+func ed25519Key(t *testing.T) (ed25519.PublicKey, []byte) {
+	t.Helper()
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return public, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func TestSignArtifactsGPG(t *testing.T) {
+	tests := []struct {
+		name       string
+		keyType    string
+		passphrase string
+	}{
+		{name: "unencrypted key", keyType: TypeGPG},
+		{name: "encrypted key", keyType: TypeGPG, passphrase: "secret"},
+		{name: "detected key type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			entity, key := gpgKey(t, tt.passphrase)
+			content := []byte("abc  demo_1.0.0_linux_amd64.tar.gz\n")
+			artifact := assets.Asset{Name: "checksums.txt", Path: writeFile(t, dir, "checksums.txt", content)}
+
+			signatures, err := SignArtifacts(Options{
+				KeyType:    tt.keyType,
+				KeyPath:    writeFile(t, dir, "key.asc", key),
+				Passphrase: tt.passphrase,
+				Artifacts:  []assets.Asset{artifact},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(signatures) != 1 || signatures[0].Name != "checksums.txt.asc" {
+				t.Fatalf("signatures = %+v", signatures)
+			}
+
+			sig, err := os.ReadFile(signatures[0].Path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			keyring := openpgp.EntityList{entity}
+			if _, err := openpgp.CheckArmoredDetachedSignature(keyring, bytes.NewReader(content), bytes.NewReader(sig), nil); err != nil {
+				t.Errorf("signature does not verify: %v", err)
+			}
+			if _, err := openpgp.CheckArmoredDetachedSignature(keyring, strings.NewReader("tampered"), bytes.NewReader(sig), nil); err == nil {
+				t.Error("signature verifies for different content")
+			}
+		})
+	}
+}
+
+func TestSignArtifactsEd25519(t *testing.T) {
+	dir := t.TempDir()
+	public, key := ed25519Key(t)
+	content := []byte("abc  demo_1.0.0_linux_amd64.tar.gz\n")
+	artifact := assets.Asset{Name: "checksums.txt", Path: writeFile(t, dir, "checksums.txt", content)}
+
+	signatures, err := SignArtifacts(Options{
+		KeyPath:   writeFile(t, dir, "key.pem", key),
+		Artifacts: []assets.Asset{artifact},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(signatures) != 1 || signatures[0].Name != "checksums.txt.minisig" {
+		t.Fatalf("signatures = %+v", signatures)
+	}
+	data, err := os.ReadFile(signatures[0].Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Verify the minisign format: untrusted comment, signature with the
+	// algorithm and key ID, trusted comment and global signature
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[0], "untrusted comment: ") || !strings.HasPrefix(lines[2], "trusted comment: ") {
+		t.Fatalf("unexpected signature file:\n%s", data)
+	}
+	sig, err := base64.StdEncoding.DecodeString(lines[1])
+	if err != nil || len(sig) != 2+8+ed25519.SignatureSize {
+		t.Fatalf("invalid signature line %q: %v", lines[1], err)
+	}
+	global, err := base64.StdEncoding.DecodeString(lines[3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	trusted := strings.TrimPrefix(lines[2], "trusted comment: ")
+	if !strings.Contains(trusted, "file:checksums.txt") {
+		t.Errorf("trusted comment %q does not name the file", trusted)
+	}
+
+	if string(sig[:2]) != "ED" {
+		t.Errorf("algorithm = %q, want ED", sig[:2])
+	}
+	hash := blake2b.Sum512(content)
+	if !ed25519.Verify(public, hash[:], sig[10:]) {
+		t.Error("signature does not verify")
+	}
+	if !ed25519.Verify(public, append(append([]byte{}, sig[10:]...), trusted...), global) {
+		t.Error("global signature does not verify")
+	}
+
+	// The public key carries the same key ID
+	signer, err := NewSigner(TypeEd25519, writeFile(t, dir, "key2.pem", key), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey, err := base64.StdEncoding.DecodeString(signer.(*ed25519Signer).PublicKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(publicKey[:2]) != "Ed" || !bytes.Equal(publicKey[2:10], sig[2:10]) || !bytes.Equal(publicKey[10:], public) {
+		t.Errorf("public key %x does not match signature key ID %x", publicKey, sig[2:10])
+	}
+}
+
+func TestNewSignerErrors(t *testing.T) {
+	dir := t.TempDir()
+	_, edKey := ed25519Key(t)
+	_, gpgEncrypted := gpgKey(t, "secret")
+
+	tests := []struct {
+		name       string
+		keyType    string
+		key        []byte
+		passphrase string
+		wantErr    string
+	}{
+		{name: "passphrase for unencrypted ed25519 key", key: edKey, passphrase: "secret", wantErr: "not encrypted"},
+		{name: "missing passphrase", key: gpgEncrypted, wantErr: "no passphrase"},
+		{name: "wrong passphrase", key: gpgEncrypted, passphrase: "wrong", wantErr: "failed to decrypt"},
+		{name: "unsupported type", keyType: "rsa", key: edKey, wantErr: "unsupported"},
+		{name: "ed25519 without PEM", keyType: TypeEd25519, key: []byte("garbage"), wantErr: "PEM"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSigner(tt.keyType, writeFile(t, dir, "key", tt.key), tt.passphrase)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package tmpl
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"text/template"
+	"time"
+
+	"goreleaser-helper/internal/semver"
+)
+
+// Context contains the values available to every templated string in the
+// configuration (ldflags, hooks, paths, env values and archive names)
+type Context struct {
+	ProjectName string
+	Version     string
+	Tag         string
+	PreviousTag string
+	Commit      string
+	ShortCommit string
+	CommitDate  string
+	Date        string
+	Dirty       bool
+
+	// Per-target fields, only set while building for a specific platform
+	Os   string
+	Arch string
+	Path string
+}
+
+// New creates a template context for the given project and version,
+// populating the git fields from the repository in the working directory
+func New(projectName, version string) (Context, error) {
+	ctx := Context{
+		ProjectName: projectName,
+		Version:     version,
+		Tag:         semver.TagName(version),
+		Date:        time.Now().UTC().Format(time.RFC3339),
+	}
+
+	// Read commit information
+	commit, err := git("rev-parse", "HEAD")
+	if err != nil {
+		return Context{}, fmt.Errorf("failed to get current commit: %w", err)
+	}
+	ctx.Commit = commit
+
+	if ctx.ShortCommit, err = git("rev-parse", "--short", "HEAD"); err != nil {
+		return Context{}, fmt.Errorf("failed to get short commit: %w", err)
+	}
+	if ctx.CommitDate, err = git("log", "-1", "--format=%cI", "HEAD"); err != nil {
+		return Context{}, fmt.Errorf("failed to get commit date: %w", err)
+	}
+
+	// Previous tag is the most recent tag that is not the release tag; a
+	// repository without tags simply has no previous tag
+	if previous, err := git("describe", "--tags", "--abbrev=0", "--exclude", ctx.Tag); err == nil {
+		ctx.PreviousTag = previous
+	}
+
+	// Check for uncommitted changes
+	status, err := git("status", "--porcelain")
+	if err != nil {
+		return Context{}, fmt.Errorf("failed to get worktree status: %w", err)
+	}
+	ctx.Dirty = status != ""
+
+	return ctx, nil
+}
+
+// SnapshotVersion returns the version used for snapshot builds, derived
+// from the abbreviated HEAD commit
+func SnapshotVersion() (string, error) {
+	shortCommit, err := git("rev-parse", "--short", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("failed to get short commit: %w", err)
+	}
+	return "0.0.0-SNAPSHOT-" + shortCommit, nil
+}
+
+// WithPlatform returns a copy of the context for the given target platform
+func (c Context) WithPlatform(goos, arch string) Context {
+	c.Os = goos
+	c.Arch = arch
+	return c
+}
+
+// WithPath returns a copy of the context with the target output path set
+func (c Context) WithPath(path string) Context {
+	c.Path = path
+	return c
+}
+
+// Apply renders the template string s against the context
+func (c Context) Apply(s string) (string, error) {
+	if !strings.Contains(s, "{{") {
+		return s, nil
+	}
+
+	t, err := template.New("").Option("missingkey=error").Parse(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid template %q: %w", s, err)
+	}
+	var buf strings.Builder
+	if err := t.Execute(&buf, c); err != nil {
+		return "", fmt.Errorf("failed to process template %q: %w", s, err)
+	}
+	return buf.String(), nil
+}
+
+func git(args ...string) (string, error) {
+	output, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}